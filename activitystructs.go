@@ -29,40 +29,38 @@ type Activity struct {
 	// Type of activity. One of these values: contactRelationUpdate, conversationUpdate, deleteUserData,
 	// message, ping, typing, endOfConversation. For details about activity types, see Activities overview:
 	// 	https://docs.microsoft.com/en-us/bot-framework/rest-api/bot-framework-rest-connector-activities
-	Type string `json:"type,omitempty"`
+	Type ActivityType `json:"type,omitempty"`
 	// The action to apply or that was applied. Use the type property to determine context for the action.
 	// For example, if type is contactRelationUpdate, the value of the action property would be add if the
 	// user added your bot to their contacts list, or remove if they removed your bot from their contacts list.
-	Action string `json:"action,omitempty"`
+	Action ActivityAction `json:"action,omitempty"`
 	// ID that uniquely identifies the activity on the channel.
 	ID string `json:"id,omitempty"`
 	// Date and time that the message was sent in the UTC time zone, expressed in ISO-8601 format.
-	Timestamp time.Time `json:"timestamp,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
 	// An ID that uniquely identifies the channel. Set by the channel.
 	ChannelID string `json:"channelId,omitempty"`
 	// URL that specifies the channel's service endpoint. Set by the channel.
 	ServiceURL string `json:"serviceUrl,omitempty"`
 	// A ChannelAccount object that specifies the sender of the message.
-	From ChannelAccount `json:"from,omitempty"`
+	From *ChannelAccount `json:"from,omitempty"`
 	// A ConversationAccount object that defines the conversation to which the activity belongs.
-	Conversation ConversationAccount `json:"conversation,omitempty"`
+	Conversation *ConversationAccount `json:"conversation,omitempty"`
 	// A ChannelAccount object that specifies the recipient of the message.
-	Recipient ChannelAccount `json:"recipient,omitempty"`
+	Recipient *ChannelAccount `json:"recipient,omitempty"`
 	// Array of Attachment objects that defines additional information to include in the message. Each
 	// attachment may be either a media file (e.g., audio, video, image, file) or a rich card.
 	Attachments []Attachment `json:"attachments,omitempty"`
 	// Layout of the rich card attachments that the message includes. One of these values: carousel, list.
 	// For more information about rich card attachments, see Add rich card attachments to messages:
 	// 	https://docs.microsoft.com/en-us/bot-framework/rest-api/bot-framework-rest-connector-add-rich-cards
-	AttachmentLayout string `json:"attachmentLayout,omitempty"`
+	AttachmentLayout AttachmentLayout `json:"attachmentLayout,omitempty"`
 	// An object that contains channel-specific content. Some channels provide features that require additional
 	// information that cannot be represented using the attachment schema. For those cases, set this property to
 	// the channel-specific content as defined in the channel's documentation. For more information, see Implement
 	// channel-specific functionality:
 	// 	https://docs.microsoft.com/en-us/bot-framework/rest-api/bot-framework-rest-connector-channeldata
 	ChannelData interface{} `json:"channelData,omitempty"`
-	// An ID that uniquely identifies the channel. Set by the channel.
-	ChannelId string `json:"channelId,omitempty"`
 	// Array of objects that represents the entities that were mentioned in the message. Objects in this array
 	// may be any Schema.org object. For example, the array may include Mention objects that identify someone
 	// who was mentioned in the conversation and Place objects that identify a place that was mentioned in the
@@ -72,7 +70,7 @@ type Activity struct {
 	HistoryDisclosed bool `json:"historyDisclosed,omitempty"`
 	// Value that indicates whether your bot is accepting, expecting, or ignoring user input after the message
 	// is delivered to the client. One of these values: acceptingInput, expectingInput, ignoringInput.
-	InputHint string `json:"inputHint,omitempty"`
+	InputHint InputHint `json:"inputHint,omitempty"`
 	// Locale of the language that should be used to display text within the message, in the format
 	// <language>-<country>. The channel uses this property to indicate the user's language, so that your bot
 	// may specify display strings in that language. Default value is en-US.
@@ -86,7 +84,7 @@ type Activity struct {
 	// only if activity type is "conversationUpdate" and users left the conversation.
 	MembersRemoved []ChannelAccount `json:"membersRemoved,omitempty"`
 	// A ConversationReference object that defines a particular point in a conversation.
-	RelatesTo ConversationReference `json:"relatesTo,omitempty"`
+	RelatesTo *ConversationReference `json:"relatesTo,omitempty"`
 	// The ID of the message to which this message replies. To reply to a message that the user sent, set this
 	// property to the ID of the user's message. Not all channels support threaded replies. In these cases, the
 	// channel will ignore this property and use time ordered semantics (timestamp) to append the message to the
@@ -106,9 +104,65 @@ type Activity struct {
 	Text string `json:"text,omitempty"`
 	// Format of the message's text. One of these values: markdown, plain, xml. For details about text format,
 	// see Create messages: https://docs.microsoft.com/en-us/bot-framework/rest-api/bot-framework-rest-connector-create-messages
-	TextFormat string `json:"textFormat,omitempty"`
+	TextFormat TextFormat `json:"textFormat,omitempty"`
 	// Topic of the conversation to which the activity belongs.
 	TopicName string `json:"topicName,omitempty"`
+	// Name of the operation of an invoke or event activity.
+	Name string `json:"name,omitempty"`
+	// Value that is associated with the activity, for example the payload of an invoke or event activity.
+	Value interface{} `json:"value,omitempty"`
+	// The type of the Value object of the activity.
+	ValueType string `json:"valueType,omitempty"`
+	// Descriptive label of the activity.
+	Label string `json:"label,omitempty"`
+	// A string which identifies the caller of the bot. Set by the Bot Framework and not by the channel.
+	CallerId string `json:"callerId,omitempty"`
+	// Date and time at which the activity should be considered expired and should not be presented to the recipient.
+	Expiration *time.Time `json:"expiration,omitempty"`
+	// The importance of the activity. One of these values: low, normal, high.
+	Importance Importance `json:"importance,omitempty"`
+	// A delivery hint to signal alternate delivery paths to the recipient. One of these values: normal,
+	// notification, expectReplies, ephemeral. Default value is normal.
+	DeliveryMode DeliveryMode `json:"deliveryMode,omitempty"`
+	// Array of TextHighlight objects which reference the parts of the text of the activity the replyToId refers to.
+	TextHighlights []TextHighlight `json:"textHighlights,omitempty"`
+	// A SemanticAction object that represents a programmatic action which is requested by the activity.
+	SemanticAction *SemanticAction `json:"semanticAction,omitempty"`
+	// The name of the local timezone of the message, expressed in IANA Time Zone database format.
+	// For example, America/Los_Angeles.
+	LocalTimezone string `json:"localTimezone,omitempty"`
+	// Array of phrases which help the speech recognition of a speech-enabled channel to recognize the expected
+	// user input.
+	ListenFor []string `json:"listenFor,omitempty"`
+	// Code indicating why the conversation has ended. Only used by endOfConversation activities.
+	Code EndOfConversationCode `json:"code,omitempty"`
+	// Array of MessageReaction objects which were added to the activity the replyToId refers to. Only used by
+	// messageReaction activities.
+	ReactionsAdded []MessageReaction `json:"reactionsAdded,omitempty"`
+	// Array of MessageReaction objects which were removed from the activity the replyToId refers to. Only used by
+	// messageReaction activities.
+	ReactionsRemoved []MessageReaction `json:"reactionsRemoved,omitempty"`
+}
+
+type MessageReaction struct {
+	// Type of the reaction, for example like or plusOne.
+	Type MessageReactionType `json:"type,omitempty"`
+}
+
+type TextHighlight struct {
+	// Text which is highlighted in the text of the referenced activity.
+	Text string `json:"text,omitempty"`
+	// The occurrence of the text in the text of the referenced activity. The first occurrence is 1.
+	Occurrence int `json:"occurrence,omitempty"`
+}
+
+type SemanticAction struct {
+	// ID of the action.
+	ID string `json:"id,omitempty"`
+	// State of the action. One of these values: start, continue, done.
+	State string `json:"state,omitempty"`
+	// Entities which are associated with the action. Each entity may be any Schema.org object.
+	Entities map[string]interface{} `json:"entities,omitempty"`
 }
 
 type SuggestedActions struct {
@@ -121,7 +175,7 @@ type SuggestedActions struct {
 type CardAction struct {
 	// Type of action to perform. For a list of valid values, see Add rich card attachments to messages:
 	// 	https://docs.microsoft.com/en-us/bot-framework/rest-api/bot-framework-rest-connector-add-rich-cards
-	Type string `json:"type,omitempty"`
+	Type CardActionType `json:"type,omitempty"`
 	// Text of the button. Only applicable for a button's action.
 	Title string `json:"title,omitempty"`
 	// URL of an image to display on the button. Only applicable for a button's action.
@@ -129,21 +183,27 @@ type CardAction struct {
 	// Contents of the action. The value of this property will vary according to the action type. For more
 	// information, see Add rich card attachments to messages.
 	Value string `json:"value,omitempty"`
+	// Text for this action. Only applicable for a messageBack action.
+	Text string `json:"text,omitempty"`
+	// Text to display in the chat feed if the button is clicked. Only applicable for a messageBack action.
+	DisplayText string `json:"displayText,omitempty"`
+	// Channel-specific data which is associated with the action.
+	ChannelData interface{} `json:"channelData,omitempty"`
 }
 
 type ConversationReference struct {
 	// ID that uniquely identifies the activity that this object references.
 	ActivityID string `json:"activityId,omitempty"`
 	// A ChannelAccount object that identifies the bot in the conversation that this object references.
-	Bot ChannelAccount `json:"bot,omitempty"`
+	Bot *ChannelAccount `json:"bot,omitempty"`
 	// An ID that uniquely identifies the channel in the conversation that this object references.
 	ChannelID string `json:"channelId,omitempty"`
 	// A ConversationAccount object that defines the conversation that this object references.
-	Conversation ConversationAccount `json:"conversation,omitempty"`
+	Conversation *ConversationAccount `json:"conversation,omitempty"`
 	// URL that specifies the channel's service endpoint in the conversation that this object references.
 	ServiceUrl string `json:"serviceUrl,omitempty"`
 	// A ChannelAccount object that identifies the user in the conversation that this object references.
-	User ChannelAccount `json:"user,omitempty"`
+	User *ChannelAccount `json:"user,omitempty"`
 }
 
 type Attachment struct {
@@ -162,8 +222,9 @@ type Attachment struct {
 	// the URL that represents the location of the image. Supported protocols are: HTTP, HTTPS, File, and Data.
 	ContentUrl string `json:"contentUrl,omitempty"`
 	// The content of the attachment. If the attachment is a rich card, set this property to the rich card
-	// object. This property and the contentUrl property are mutually exclusive.
-	Content AttachmentContent `json:"content,omitempty"`
+	// object, for example an AttachmentContent. This property and the contentUrl property are mutually exclusive.
+	// Decoded content is a map[string]interface{} so fields of cards which are not declared here are kept.
+	Content interface{} `json:"content,omitempty"`
 	// Name of the attachment.
 	Name string `json:"name,omitempty"`
 	// URL to a thumbnail image that the channel can use if it supports using an alternative, smaller form of
@@ -231,6 +292,8 @@ type ChannelAccount struct {
 	ID string `json:"id,omitempty"`
 	// Name of the bot or user.
 	Name string `json:"name,omitempty"`
+	// Role of the entity behind the account. One of these values: user, bot.
+	Role RoleType `json:"role,omitempty"`
 }
 
 type ConversationAccount struct {
@@ -244,3 +307,45 @@ type ConversationAccount struct {
 	// A display name that can be used to identify the conversation.
 	Name string `json:"name,omitempty"`
 }
+
+type ConversationParameters struct {
+	// Flag to indicate whether or not this is a group conversation. Set to true if this is a group conversation;
+	// otherwise, false. The default is false.
+	IsGroup bool `json:"isGroup,omitempty"`
+	// A ChannelAccount object that identifies the bot.
+	Bot *ChannelAccount `json:"bot,omitempty"`
+	// Array of ChannelAccount objects that identifies the members of the conversation. This list must contain a
+	// single user unless isGroup is set to true. This list may include other bots.
+	Members []ChannelAccount `json:"members,omitempty"`
+	// Title of the conversation.
+	TopicName string `json:"topicName,omitempty"`
+	// An Activity object that is sent to the conversation when it is created.
+	Activity *Activity `json:"activity,omitempty"`
+	// An object that contains channel-specific content which is used to create the conversation.
+	ChannelData interface{} `json:"channelData,omitempty"`
+}
+
+type ConversationResourceResponse struct {
+	// ID of the activity (if sent).
+	ActivityID string `json:"activityId,omitempty"`
+	// Service endpoint where operations concerning the conversation may be performed.
+	ServiceURL string `json:"serviceUrl,omitempty"`
+	// ID of the conversation.
+	ID string `json:"id,omitempty"`
+}
+
+type ResourceResponse struct {
+	// ID that uniquely identifies the resource.
+	ID string `json:"id,omitempty"`
+}
+
+type AttachmentData struct {
+	// The content type of the attachment.
+	Type string `json:"type,omitempty"`
+	// Name of the attachment.
+	Name string `json:"name,omitempty"`
+	// Binary data that represents the contents of the original version of the file.
+	OriginalBase64 []byte `json:"originalBase64,omitempty"`
+	// Binary data that represents the contents of the thumbnail version of the file.
+	ThumbnailBase64 []byte `json:"thumbnailBase64,omitempty"`
+}
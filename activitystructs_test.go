@@ -0,0 +1,231 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// A message which was sent by the Skype channel with an image and a hero card.
+const skypeMessagePayload = `{
+  "type": "message",
+  "id": "1508875187370",
+  "timestamp": "2017-10-24T19:59:47.403Z",
+  "localTimestamp": "2017-10-24T21:59:47.403+02:00",
+  "serviceUrl": "https://smba.trafficmanager.net/apis/",
+  "channelId": "skype",
+  "from": {"id": "29:1EcZ2JbKU4aYL-8uk_8t9oKUuQsUCzuBkoZr8vQJx0ns", "name": "Michi"},
+  "conversation": {"id": "29:1EcZ2JbKU4aYL-8uk_8t9oKUuQsUCzuBkoZr8vQJx0ns"},
+  "recipient": {"id": "28:8c5c2cb1-ea28-4f1b-9b0e-59b0a2e2c8e1", "name": "skypeapi"},
+  "textFormat": "plain",
+  "text": "show me a card",
+  "attachments": [
+    {"contentType": "image/png", "contentUrl": "https://example.com/image.png", "name": "image.png"},
+    {
+      "contentType": "application/vnd.microsoft.card.hero",
+      "content": {
+        "title": "Hero",
+        "images": [{"url": "https://example.com/hero.png"}],
+        "buttons": [{"type": "imBack", "title": "Yes", "value": "yes"}],
+        "tap": {"type": "openUrl", "value": "https://example.com"}
+      }
+    }
+  ],
+  "entities": [{"locale": "de-DE", "country": "DE", "platform": "Windows", "type": "clientInfo"}],
+  "channelData": {"text": "show me a card"}
+}`
+
+// A conversation update and a message with an adaptive card which were sent by the Bot Framework Emulator.
+const emulatorConversationUpdatePayload = `{
+  "type": "conversationUpdate",
+  "id": "f1b5e2c0-6d5a-11e9-b2c7-4b2e8d8b6a1e",
+  "timestamp": "2019-05-03T12:34:56.789Z",
+  "localTimestamp": "2019-05-03T14:34:56+02:00",
+  "localTimezone": "Europe/Berlin",
+  "serviceUrl": "http://localhost:52391",
+  "channelId": "emulator",
+  "from": {"id": "3c4e6a2b-1d7f-4a0e-9c1b-5f6e7d8c9a0b", "name": "User", "role": "user"},
+  "conversation": {"id": "f0e1d2c3-6d5a-11e9-9a1b-2f3e4d5c6b7a|livechat"},
+  "recipient": {"id": "1b2c3d4e-5f60-11e9-8a7b-6c5d4e3f2a1b", "name": "Bot", "role": "bot"},
+  "membersAdded": [
+    {"id": "1b2c3d4e-5f60-11e9-8a7b-6c5d4e3f2a1b", "name": "Bot"},
+    {"id": "3c4e6a2b-1d7f-4a0e-9c1b-5f6e7d8c9a0b", "name": "User"}
+  ]
+}`
+
+const emulatorAdaptiveCardPayload = `{
+  "type": "message",
+  "id": "0a1b2c3d-6d5b-11e9-b2c7-4b2e8d8b6a1e",
+  "timestamp": "2019-05-03T12:35:10.001Z",
+  "serviceUrl": "http://localhost:52391",
+  "channelId": "emulator",
+  "from": {"id": "1b2c3d4e-5f60-11e9-8a7b-6c5d4e3f2a1b", "name": "Bot", "role": "bot"},
+  "conversation": {"id": "f0e1d2c3-6d5a-11e9-9a1b-2f3e4d5c6b7a|livechat"},
+  "recipient": {"id": "3c4e6a2b-1d7f-4a0e-9c1b-5f6e7d8c9a0b", "role": "user"},
+  "replyToId": "f1b5e2c0-6d5a-11e9-b2c7-4b2e8d8b6a1e",
+  "inputHint": "acceptingInput",
+  "attachmentLayout": "carousel",
+  "attachments": [
+    {
+      "contentType": "application/vnd.microsoft.card.adaptive",
+      "content": {
+        "$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+        "type": "AdaptiveCard",
+        "version": "1.0",
+        "body": [],
+        "actions": [{"type": "Action.Submit", "title": "Send", "data": {"choice": 1}}]
+      }
+    }
+  ],
+  "suggestedActions": {
+    "to": ["3c4e6a2b-1d7f-4a0e-9c1b-5f6e7d8c9a0b"],
+    "actions": [
+      {"type": "imBack", "title": "Red", "value": "red"},
+      {"type": "messageBack", "title": "Blue", "text": "blue", "displayText": "I like blue", "channelData": {"color": "blue"}}
+    ]
+  },
+  "importance": "high",
+  "deliveryMode": "notification",
+  "expiration": "2019-05-04T00:00:00Z",
+  "textHighlights": [{"text": "Red", "occurrence": 1}],
+  "listenFor": ["red", "blue"],
+  "semanticAction": {"id": "colors", "state": "start", "entities": {"color": {"type": "color", "value": "red"}}},
+  "name": "colorPicker",
+  "value": {"choice": 1},
+  "valueType": "application/json",
+  "label": "Colors",
+  "callerId": "urn:botframework:azure"
+}`
+
+// A reaction and the end of a conversation which were sent by the Bot Framework Emulator.
+const emulatorMessageReactionPayload = `{
+  "type": "messageReaction",
+  "id": "2b3c4d5e-6d5b-11e9-b2c7-4b2e8d8b6a1e",
+  "timestamp": "2019-05-03T12:35:20.002Z",
+  "serviceUrl": "http://localhost:52391",
+  "channelId": "emulator",
+  "from": {"id": "3c4e6a2b-1d7f-4a0e-9c1b-5f6e7d8c9a0b", "name": "User", "role": "user"},
+  "conversation": {"id": "f0e1d2c3-6d5a-11e9-9a1b-2f3e4d5c6b7a|livechat"},
+  "recipient": {"id": "1b2c3d4e-5f60-11e9-8a7b-6c5d4e3f2a1b", "name": "Bot", "role": "bot"},
+  "replyToId": "0a1b2c3d-6d5b-11e9-b2c7-4b2e8d8b6a1e",
+  "reactionsAdded": [{"type": "like"}],
+  "reactionsRemoved": [{"type": "plusOne"}]
+}`
+
+const emulatorEndOfConversationPayload = `{
+  "type": "endOfConversation",
+  "id": "3c4d5e6f-6d5b-11e9-b2c7-4b2e8d8b6a1e",
+  "timestamp": "2019-05-03T12:36:00.003Z",
+  "serviceUrl": "http://localhost:52391",
+  "channelId": "emulator",
+  "from": {"id": "3c4e6a2b-1d7f-4a0e-9c1b-5f6e7d8c9a0b", "name": "User", "role": "user"},
+  "conversation": {"id": "f0e1d2c3-6d5a-11e9-9a1b-2f3e4d5c6b7a|livechat"},
+  "recipient": {"id": "1b2c3d4e-5f60-11e9-8a7b-6c5d4e3f2a1b", "name": "Bot", "role": "bot"},
+  "code": "userCancelled",
+  "text": "The user left"
+}`
+
+func TestActivityRoundTrip(t *testing.T) {
+	payloads := map[string]string{
+		"skype message":                skypeMessagePayload,
+		"emulator conversation update": emulatorConversationUpdatePayload,
+		"emulator adaptive card":       emulatorAdaptiveCardPayload,
+		"emulator message reaction":    emulatorMessageReactionPayload,
+		"emulator end of conversation": emulatorEndOfConversationPayload,
+	}
+	for name, payload := range payloads {
+		var activity Activity
+		if err := json.Unmarshal([]byte(payload), &activity); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		jsonEncoded, err := json.Marshal(&activity)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		var expected, actual interface{}
+		json.Unmarshal([]byte(payload), &expected)
+		json.Unmarshal(jsonEncoded, &actual)
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%v: the activity changed during the round trip:\n%s", name, jsonEncoded)
+		}
+		if activity.ChannelID == "" || activity.ChannelID != expected.(map[string]interface{})["channelId"] {
+			t.Errorf("%v: got channel id %q", name, activity.ChannelID)
+		}
+	}
+}
+
+func TestActivityDecodesTypedFields(t *testing.T) {
+	var activity Activity
+	if err := json.Unmarshal([]byte(emulatorConversationUpdatePayload), &activity); err != nil {
+		t.Fatal(err)
+	}
+	if !activity.IsBotAdded() || activity.From.Role != UserRole || activity.Recipient.Role != BotRole {
+		t.Errorf("got %+v", activity)
+	}
+	if err := json.Unmarshal([]byte(emulatorAdaptiveCardPayload), &activity); err != nil {
+		t.Fatal(err)
+	}
+	if activity.Importance != HighImportance || activity.DeliveryMode != NotificationDeliveryMode ||
+		activity.InputHint != AcceptingInputHint || activity.SemanticAction.ID != "colors" || activity.Expiration == nil {
+		t.Errorf("got %+v", activity)
+	}
+	if cardAction := activity.SuggestedActions.Actions[1]; cardAction.Type != MessageBackCardAction ||
+		cardAction.Text != "blue" || cardAction.DisplayText != "I like blue" || cardAction.ChannelData == nil {
+		t.Errorf("got card action %+v", cardAction)
+	}
+	activity = Activity{}
+	if err := json.Unmarshal([]byte(emulatorMessageReactionPayload), &activity); err != nil {
+		t.Fatal(err)
+	}
+	if activity.Type != MessageReactionActivityType || len(activity.ReactionsAdded) != 1 ||
+		activity.ReactionsAdded[0].Type != LikeMessageReaction || len(activity.ReactionsRemoved) != 1 ||
+		activity.ReactionsRemoved[0].Type != PlusOneMessageReaction {
+		t.Errorf("got %+v", activity)
+	}
+	activity = Activity{}
+	if err := json.Unmarshal([]byte(emulatorEndOfConversationPayload), &activity); err != nil {
+		t.Fatal(err)
+	}
+	if activity.Type != EndOfConversationActivityType || activity.Code != UserCancelledEndOfConversationCode {
+		t.Errorf("got %+v", activity)
+	}
+}
+
+func TestActivityOmitsEmptyFields(t *testing.T) {
+	activity := &Activity{
+		Type:        MessageActivityType,
+		Attachments: []Attachment{{ContentType: "image/png", ContentUrl: "https://example.com/image.png"}},
+	}
+	jsonEncoded, err := json.Marshal(activity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"message","attachments":[{"contentType":"image/png","contentUrl":"https://example.com/image.png"}]}`
+	if string(jsonEncoded) != expected {
+		t.Errorf("got %s, expected %s", jsonEncoded, expected)
+	}
+}
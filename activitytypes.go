@@ -0,0 +1,219 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+// The type of an Activity. For details about the activity types see:
+// https://docs.microsoft.com/en-us/bot-framework/rest-api/bot-framework-rest-connector-activities
+type ActivityType string
+
+const (
+	MessageActivityType               ActivityType = "message"
+	ContactRelationUpdateActivityType ActivityType = "contactRelationUpdate"
+	ConversationUpdateActivityType    ActivityType = "conversationUpdate"
+	TypingActivityType                ActivityType = "typing"
+	EndOfConversationActivityType     ActivityType = "endOfConversation"
+	EventActivityType                 ActivityType = "event"
+	InvokeActivityType                ActivityType = "invoke"
+	DeleteUserDataActivityType        ActivityType = "deleteUserData"
+	MessageUpdateActivityType         ActivityType = "messageUpdate"
+	MessageDeleteActivityType         ActivityType = "messageDelete"
+	InstallationUpdateActivityType    ActivityType = "installationUpdate"
+	MessageReactionActivityType       ActivityType = "messageReaction"
+	SuggestionActivityType            ActivityType = "suggestion"
+	TraceActivityType                 ActivityType = "trace"
+	HandoffActivityType               ActivityType = "handoff"
+	PingActivityType                  ActivityType = "ping"
+)
+
+// The action of a contactRelationUpdate or installationUpdate Activity.
+type ActivityAction string
+
+const (
+	AddAction    ActivityAction = "add"
+	RemoveAction ActivityAction = "remove"
+)
+
+// Declares whether the bot is accepting, expecting or ignoring user input after the message is delivered.
+type InputHint string
+
+const (
+	AcceptingInputHint InputHint = "acceptingInput"
+	ExpectingInputHint InputHint = "expectingInput"
+	IgnoringInputHint  InputHint = "ignoringInput"
+)
+
+// The format of the text of a message.
+type TextFormat string
+
+const (
+	MarkdownTextFormat TextFormat = "markdown"
+	PlainTextFormat    TextFormat = "plain"
+	XmlTextFormat      TextFormat = "xml"
+)
+
+// The layout of the rich card attachments of a message.
+type AttachmentLayout string
+
+const (
+	ListAttachmentLayout     AttachmentLayout = "list"
+	CarouselAttachmentLayout AttachmentLayout = "carousel"
+)
+
+// The type of a CardAction. For details about the action types see:
+// https://docs.microsoft.com/en-us/bot-framework/rest-api/bot-framework-rest-connector-add-rich-cards
+type CardActionType string
+
+const (
+	OpenUrlCardAction      CardActionType = "openUrl"
+	ImBackCardAction       CardActionType = "imBack"
+	PostBackCardAction     CardActionType = "postBack"
+	PlayAudioCardAction    CardActionType = "playAudio"
+	PlayVideoCardAction    CardActionType = "playVideo"
+	ShowImageCardAction    CardActionType = "showImage"
+	DownloadFileCardAction CardActionType = "downloadFile"
+	SigninCardAction       CardActionType = "signin"
+	CallCardAction         CardActionType = "call"
+	MessageBackCardAction  CardActionType = "messageBack"
+)
+
+// The importance of an Activity.
+type Importance string
+
+const (
+	LowImportance    Importance = "low"
+	NormalImportance Importance = "normal"
+	HighImportance   Importance = "high"
+)
+
+// The delivery hint of an Activity.
+type DeliveryMode string
+
+const (
+	NormalDeliveryMode        DeliveryMode = "normal"
+	NotificationDeliveryMode  DeliveryMode = "notification"
+	ExpectRepliesDeliveryMode DeliveryMode = "expectReplies"
+	EphemeralDeliveryMode     DeliveryMode = "ephemeral"
+)
+
+// The reason of an endOfConversation Activity.
+type EndOfConversationCode string
+
+const (
+	UnknownEndOfConversationCode                 EndOfConversationCode = "unknown"
+	CompletedSuccessfullyEndOfConversationCode   EndOfConversationCode = "completedSuccessfully"
+	UserCancelledEndOfConversationCode           EndOfConversationCode = "userCancelled"
+	BotTimedOutEndOfConversationCode             EndOfConversationCode = "botTimedOut"
+	BotIssuedInvalidMessageEndOfConversationCode EndOfConversationCode = "botIssuedInvalidMessage"
+	ChannelFailedEndOfConversationCode           EndOfConversationCode = "channelFailed"
+)
+
+// The type of a MessageReaction.
+type MessageReactionType string
+
+const (
+	LikeMessageReaction    MessageReactionType = "like"
+	PlusOneMessageReaction MessageReactionType = "plusOne"
+)
+
+// The role of the entity behind a ChannelAccount.
+type RoleType string
+
+const (
+	UserRole RoleType = "user"
+	BotRole  RoleType = "bot"
+)
+
+// Returns true if the activity is a message.
+func (activity *Activity) IsMessage() bool {
+	return activity.Type == MessageActivityType
+}
+
+// Returns true if the activity is a conversation update.
+func (activity *Activity) IsConversationUpdate() bool {
+	return activity.Type == ConversationUpdateActivityType
+}
+
+// Returns true if the activity is a contact relation update.
+func (activity *Activity) IsContactRelationUpdate() bool {
+	return activity.Type == ContactRelationUpdateActivityType
+}
+
+// Returns true if the activity is a typing indicator.
+func (activity *Activity) IsTyping() bool {
+	return activity.Type == TypingActivityType
+}
+
+// Returns true if the MembersAdded contain the recipient of the activity which is the bot itself.
+func (activity *Activity) MembersAddedIncludesBot() bool {
+	return activity.Recipient != nil && containsAccount(activity.MembersAdded, *activity.Recipient)
+}
+
+// Returns true if the MembersRemoved contain the recipient of the activity which is the bot itself.
+func (activity *Activity) MembersRemovedIncludesBot() bool {
+	return activity.Recipient != nil && containsAccount(activity.MembersRemoved, *activity.Recipient)
+}
+
+// Returns true if the activity is a conversation update which adds the bot to the conversation.
+func (activity *Activity) IsBotAdded() bool {
+	return activity.IsConversationUpdate() && activity.MembersAddedIncludesBot()
+}
+
+// Returns true if the activity is a conversation update which removes the bot from the conversation.
+func (activity *Activity) IsBotRemoved() bool {
+	return activity.IsConversationUpdate() && activity.MembersRemovedIncludesBot()
+}
+
+// Returns true if the activity is a contact relation update which adds the bot to the contacts of a user.
+func (activity *Activity) IsBotAddedToContacts() bool {
+	return activity.IsContactRelationUpdate() && activity.Action == AddAction
+}
+
+// Returns true if the activity is a contact relation update which removes the bot from the contacts of a user.
+func (activity *Activity) IsBotRemovedFromContacts() bool {
+	return activity.IsContactRelationUpdate() && activity.Action == RemoveAction
+}
+
+// Returns the ID of the conversation or an empty string if the activity has no conversation.
+func (activity *Activity) conversationId() string {
+	if activity.Conversation == nil {
+		return ""
+	}
+	return activity.Conversation.ID
+}
+
+// Returns the ID of the sender or an empty string if the activity has no sender.
+func (activity *Activity) fromId() string {
+	if activity.From == nil {
+		return ""
+	}
+	return activity.From.ID
+}
+
+// Returns the ID of the recipient or an empty string if the activity has no recipient.
+func (activity *Activity) recipientId() string {
+	if activity.Recipient == nil {
+		return ""
+	}
+	return activity.Recipient.ID
+}
@@ -24,11 +24,12 @@ SOFTWARE.
 package skypeapi
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"encoding/json"
 	"fmt"
-	"bytes"
+	"strings"
 )
 
 type MessageListener interface {
@@ -43,31 +44,68 @@ type TokenResponse struct {
 }
 
 const (
-	unexpectedHttpStatusCodeTemplate = "The microsoft servers returned an unexpected http status code: %v"
-
 	requestTokenUrl      = "https://login.microsoftonline.com/botframework.com/oauth2/v2.0/token"
+	requestTokenScope    = "https://api.botframework.com/.default"
 	replyMessageTemplate = "%vv3/conversations/%v/activities/%v"
 )
 
+// Requests an access token with the DefaultConfiguration.
 func RequestAccessToken(microsoftAppId string, microsoftAppPassword string) (TokenResponse, error) {
+	return DefaultConfiguration.RequestAccessTokenWithContext(context.Background(), microsoftAppId, microsoftAppPassword)
+}
+
+// Requests an access token with the DefaultConfiguration. The request is canceled if the ctx is done.
+func RequestAccessTokenWithContext(ctx context.Context, microsoftAppId string, microsoftAppPassword string) (TokenResponse, error) {
+	return DefaultConfiguration.RequestAccessTokenWithContext(ctx, microsoftAppId, microsoftAppPassword)
+}
+
+// Requests an access token from the TokenUrl of the configuration.
+func (configuration *Configuration) RequestAccessToken(microsoftAppId string, microsoftAppPassword string) (TokenResponse, error) {
+	return configuration.RequestAccessTokenWithContext(context.Background(), microsoftAppId, microsoftAppPassword)
+}
+
+// Requests an access token from the TokenUrl of the configuration. The request is canceled if the ctx is done.
+func (configuration *Configuration) RequestAccessTokenWithContext(ctx context.Context, microsoftAppId string, microsoftAppPassword string) (TokenResponse, error) {
 	var tokenResponse TokenResponse
 	values := url.Values{}
 	values.Set("grant_type", "client_credentials")
 	values.Set("client_id", microsoftAppId)
 	values.Set("client_secret", microsoftAppPassword)
-	values.Set("scope", "https://api.botframework.com/.default")
-	if response, err := http.PostForm(requestTokenUrl, values); err != nil {
+	values.Set("scope", configuration.TokenScope)
+	// requesting a token has no side effects so every failure could be retried
+	if response, err := sendWithRetry(ctx, configuration.httpClient(), configuration.RetryPolicy, true, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, configuration.TokenUrl, strings.NewReader(values.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		return req, err
+	}); err != nil {
 		return tokenResponse, err
 	} else if response.StatusCode == http.StatusOK {
 		defer response.Body.Close()
-		json.NewDecoder(response.Body).Decode(&tokenResponse)
+		err := json.NewDecoder(response.Body).Decode(&tokenResponse)
 		return tokenResponse, err
 	} else {
-		return tokenResponse, fmt.Errorf(unexpectedHttpStatusCodeTemplate, response.StatusCode)
+		defer response.Body.Close()
+		return tokenResponse, newTokenRequestError(response, configuration.TokenUrl)
 	}
 }
 
-func SendReplyMessage(activity *Activity, message, authorizationToken string) error {
+// Sends the message as a reply to the given activity. The tokenSource supplies the bearer token which is used
+// to authorize the request. Use an AccessTokenManager to get automatically refreshed tokens.
+// Returns the ResourceResponse which contains the ID the channel assigned to the reply.
+func SendReplyMessage(activity *Activity, message string, tokenSource TokenSource) (ResourceResponse, error) {
+	return SendReplyMessageWithContext(context.Background(), activity, message, tokenSource)
+}
+
+// Sends the message as a reply to the given activity like SendReplyMessage. The request is canceled if the ctx is done.
+func SendReplyMessageWithContext(ctx context.Context, activity *Activity, message string, tokenSource TokenSource) (ResourceResponse, error) {
+	return DefaultConfiguration.SendReplyMessageWithContext(ctx, activity, message, tokenSource)
+}
+
+// Sends the message as a reply to the given activity with the http.Client of the configuration. The request is
+// canceled if the ctx is done.
+func (configuration *Configuration) SendReplyMessageWithContext(ctx context.Context, activity *Activity, message string, tokenSource TokenSource) (ResourceResponse, error) {
 	responseActivity := &Activity{
 		Type:         activity.Type,
 		From:         activity.Recipient,
@@ -76,38 +114,25 @@ func SendReplyMessage(activity *Activity, message, authorizationToken string) er
 		Text:         message,
 		ReplyToID:    activity.ID,
 	}
-	replyUrl := fmt.Sprintf(replyMessageTemplate, activity.ServiceURL, activity.Conversation.ID, activity.ID)
-	return SendActivityRequest(responseActivity, replyUrl, authorizationToken)
+	replyUrl := fmt.Sprintf(replyMessageTemplate, activity.ServiceURL, activity.conversationId(), activity.ID)
+	return configuration.SendActivityRequestWithContext(ctx, responseActivity, replyUrl, tokenSource)
 }
 
-func SendActivityRequest(activity *Activity, replyUrl, authorizationToken string) error {
-	client := &http.Client{}
-	if jsonEncoded, err := json.Marshal(*activity); err != nil {
-		return err
-	} else {
-		req, err := http.NewRequest(
-			http.MethodPost,
-			replyUrl,
-			bytes.NewBuffer(*&jsonEncoded),
-		)
-		if err == nil {
-			req.Header.Set(authorizationHeaderKey, authorizationHeaderValuePrefix+authorizationToken)
-			req.Header.Set("Content-Type", "application/json")
-			resp, err := client.Do(*&req)
-			if err == nil {
-				defer resp.Body.Close()
-				var statusCode int = resp.StatusCode
-				if statusCode == http.StatusOK || statusCode == http.StatusCreated ||
-					statusCode == http.StatusAccepted || statusCode == http.StatusNoContent {
-					return nil
-				} else {
-					return fmt.Errorf(unexpectedHttpStatusCodeTemplate, statusCode)
-				}
-			} else {
-				return err
-			}
-		} else {
-			return err
-		}
-	}
+// Posts the activity to the given url. The tokenSource supplies the bearer token which is used to authorize
+// the request. Returns the ResourceResponse which contains the ID the channel assigned to the activity.
+func SendActivityRequest(activity *Activity, replyUrl string, tokenSource TokenSource) (ResourceResponse, error) {
+	return SendActivityRequestWithContext(context.Background(), activity, replyUrl, tokenSource)
+}
+
+// Posts the activity to the given url like SendActivityRequest. The request is canceled if the ctx is done.
+func SendActivityRequestWithContext(ctx context.Context, activity *Activity, replyUrl string, tokenSource TokenSource) (ResourceResponse, error) {
+	return DefaultConfiguration.SendActivityRequestWithContext(ctx, activity, replyUrl, tokenSource)
+}
+
+// Posts the activity to the given url with the http.Client of the configuration. The request is canceled if the
+// ctx is done.
+func (configuration *Configuration) SendActivityRequestWithContext(ctx context.Context, activity *Activity, replyUrl string, tokenSource TokenSource) (ResourceResponse, error) {
+	var resourceResponse ResourceResponse
+	err := sendJsonRequest(ctx, configuration, tokenSource, http.MethodPost, replyUrl, activity, &resourceResponse)
+	return resourceResponse, err
 }
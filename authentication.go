@@ -6,17 +6,15 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"bytes"
-	"time"
-	"crypto/x509"
 	"net/http"
-	"encoding/pem"
+	"context"
 )
 
 const (
 	openIdRequestPath                   string = "https://login.botframework.com/v1/.well-known/openidconfiguration"
 	authorizationHeaderValuePrefix      string = "Bearer "
 	wrongAuthorizationHeaderFormatError string = "The provided authorization header is in the wrong format: %v"
-	wrongSplitLengthError               string = "The authorize value split length with character \"%v\" is not valid: %v (%v)"
+	wrongSplitLengthError               string = "The authorize value split length with character \"%v\" is not valid: %v (%v characters)"
 	splitCharacter                      string = "."
 	issuerUrl                           string = "https://api.botframework.com"
 )
@@ -30,16 +28,18 @@ type OpenIdDocument struct {
 }
 
 type SigningKeys struct {
-	Keys []struct {
-		Kty          string `json:"kty"`
-		Use          string `json:"use"`
-		KeyId        string `json:"kid"`
-		X5T          string `json:"x5t"`
-		N            string `json:"n"`
-		E            string `json:"e"`
-		X5C          []string `json:"x5c"`
-		Endorsements []string `json:"endorsements,omitempty"`
-	} `json:"keys"`
+	Keys []SigningKey `json:"keys"`
+}
+
+type SigningKey struct {
+	Kty          string `json:"kty"`
+	Use          string `json:"use"`
+	KeyId        string `json:"kid"`
+	X5T          string `json:"x5t"`
+	N            string `json:"n"`
+	E            string `json:"e"`
+	X5C          []string `json:"x5c"`
+	Endorsements []string `json:"endorsements,omitempty"`
 }
 
 type JwtHeader struct {
@@ -55,6 +55,11 @@ type JwtPayload struct {
 	Audience     string `json:"aud"`
 	Expires      int `json:"exp"`
 	CreatedOnNbf int `json:"nbf"`
+	// The app id in tokens of the Bot Framework Emulator with version 1.0
+	AppId           string `json:"appid,omitempty"`
+	// The app id in tokens of the Bot Framework Emulator with version 2.0
+	AuthorizedParty string `json:"azp,omitempty"`
+	Version         string `json:"ver,omitempty"`
 }
 
 type MicrosoftJsonWebToken struct {
@@ -64,22 +69,38 @@ type MicrosoftJsonWebToken struct {
 	VerifySignature             []byte
 }
 
-func (microSoftJsonWebToken MicrosoftJsonWebToken) Verify(microsoftAppId string, signingKeys SigningKeys) bool {
-	if microSoftJsonWebToken.Payload.Issuer != issuerUrl {
-		return false
-	} else if microSoftJsonWebToken.Payload.Audience != microsoftAppId {
-		return false
-	} else if int64(microSoftJsonWebToken.Payload.Expires) <= time.Now().Unix() {
-		return false
-	} else {
-		return microSoftJsonWebToken.verifyCertificate(signingKeys)
-	}
+// Verifies the token with the issuer of the DefaultConfiguration. Returns an AuthorizationError if the token is not valid.
+func (microSoftJsonWebToken MicrosoftJsonWebToken) Verify(microsoftAppId string, signingKeys SigningKeys) error {
+	return microSoftJsonWebToken.VerifyWithConfiguration(DefaultConfiguration, microsoftAppId, signingKeys)
 }
 
+// Verifies the token with a TokenValidator of the configuration. The endorsements of the signing key are not
+// checked because the channel of the activity is unknown. Returns an AuthorizationError if the token is not valid.
+func (microSoftJsonWebToken MicrosoftJsonWebToken) VerifyWithConfiguration(configuration *Configuration, microsoftAppId string, signingKeys SigningKeys) error {
+	return NewTokenValidator(configuration).Validate(microSoftJsonWebToken, microsoftAppId, signingKeys, "")
+}
+
+// Fetches the SigningKeys with the DefaultConfiguration.
 func GetSigningKeys() (SigningKeys, error) {
+	return DefaultConfiguration.GetSigningKeysWithContext(context.Background())
+}
+
+// Fetches the SigningKeys with the DefaultConfiguration. The requests are canceled if the ctx is done.
+func GetSigningKeysWithContext(ctx context.Context) (SigningKeys, error) {
+	return DefaultConfiguration.GetSigningKeysWithContext(ctx)
+}
+
+// Fetches the OpenID metadata document of the configuration and the SigningKeys which are referenced by it.
+func (configuration *Configuration) GetSigningKeys() (SigningKeys, error) {
+	return configuration.GetSigningKeysWithContext(context.Background())
+}
+
+// Fetches the OpenID metadata document of the configuration and the SigningKeys which are referenced by it.
+// The requests are canceled if the ctx is done.
+func (configuration *Configuration) GetSigningKeysWithContext(ctx context.Context) (SigningKeys, error) {
 	openIdDocument := &OpenIdDocument{}
-	client := &http.Client{}
-	req, err := http.NewRequest(http.MethodGet, openIdRequestPath, nil)
+	client := configuration.httpClient()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configuration.OpenIdMetadataUrl, nil)
 	if err != nil {
 		return SigningKeys{}, err
 	} else {
@@ -88,20 +109,40 @@ func GetSigningKeys() (SigningKeys, error) {
 			return SigningKeys{}, err
 		} else {
 			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return SigningKeys{}, newConnectorError(resp, configuration.OpenIdMetadataUrl)
+			}
 			err := json.NewDecoder(resp.Body).Decode(openIdDocument)
 			if err != nil {
 				return SigningKeys{}, err
 			} else {
-				return GetSigningKeysByUrl(openIdDocument.JwksURI)
+				return configuration.GetSigningKeysByUrlWithContext(ctx, openIdDocument.JwksURI)
 			}
 		}
 	}
 }
 
+// Fetches the SigningKeys from the given url with the DefaultConfiguration.
 func GetSigningKeysByUrl(url string) (SigningKeys, error) {
+	return DefaultConfiguration.GetSigningKeysByUrlWithContext(context.Background(), url)
+}
+
+// Fetches the SigningKeys from the given url with the DefaultConfiguration. The request is canceled if the ctx is done.
+func GetSigningKeysByUrlWithContext(ctx context.Context, url string) (SigningKeys, error) {
+	return DefaultConfiguration.GetSigningKeysByUrlWithContext(ctx, url)
+}
+
+// Fetches the SigningKeys from the given url with the http.Client of the configuration.
+func (configuration *Configuration) GetSigningKeysByUrl(url string) (SigningKeys, error) {
+	return configuration.GetSigningKeysByUrlWithContext(context.Background(), url)
+}
+
+// Fetches the SigningKeys from the given url with the http.Client of the configuration. The request is canceled
+// if the ctx is done.
+func (configuration *Configuration) GetSigningKeysByUrlWithContext(ctx context.Context, url string) (SigningKeys, error) {
 	signingKeys := &SigningKeys{}
-	client := &http.Client{}
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	client := configuration.httpClient()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return SigningKeys{}, err
 	} else {
@@ -110,6 +151,9 @@ func GetSigningKeysByUrl(url string) (SigningKeys, error) {
 			return SigningKeys{}, err
 		} else {
 			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return SigningKeys{}, newConnectorError(resp, url)
+			}
 			err := json.NewDecoder(resp.Body).Decode(signingKeys)
 			if err != nil {
 				return SigningKeys{}, err
@@ -120,22 +164,6 @@ func GetSigningKeysByUrl(url string) (SigningKeys, error) {
 	}
 }
 
-func (microSoftJsonWebToken MicrosoftJsonWebToken) verifyCertificate(signingKeys SigningKeys) bool {
-	for _, key := range signingKeys.Keys {
-		if key.KeyId == microSoftJsonWebToken.Header.SigningKeyId {
-			certificateParsed := parseCertificateString(key.X5C[0])
-			block, _ := pem.Decode([]byte(certificateParsed))
-			if certificate, err := x509.ParseCertificate(block.Bytes); err != nil {
-				return false
-			} else {
-				hashed := []byte(microSoftJsonWebToken.HeaderBase64 + splitCharacter + microSoftJsonWebToken.PayloadBase64)
-				return certificate.CheckSignature(x509.SHA256WithRSA, hashed, microSoftJsonWebToken.VerifySignature) == nil
-			}
-		}
-	}
-	return false
-}
-
 func parseCertificateString(rawCertificate string) string {
 	parsedCertificate := "-----BEGIN CERTIFICATE-----\n"
 	buffer := bytes.NewBuffer(make([]byte, 64))
@@ -179,7 +207,7 @@ func ParseMicrosoftJsonWebToken(headerValue string) (MicrosoftJsonWebToken, erro
 			microsoftJsonWebToken.VerifySignature = jwtVerifySignature
 			return *microsoftJsonWebToken, nil
 		} else {
-			return *microsoftJsonWebToken, fmt.Errorf(wrongSplitLengthError, splitCharacter, len(split), parseHeaderValue)
+			return *microsoftJsonWebToken, fmt.Errorf(wrongSplitLengthError, splitCharacter, len(split), len(parsedHeaderValue))
 		}
 	}
 }
@@ -0,0 +1,40 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMicrosoftJsonWebTokenDoesNotLeakToken(t *testing.T) {
+	for _, token := range []string{"secret-credential", "secret.credential", "secret.credential.with.four"} {
+		_, err := ParseMicrosoftJsonWebToken(authorizationHeaderValuePrefix + token)
+		if err == nil {
+			t.Errorf("%v: expected an error", token)
+		} else if strings.Contains(err.Error(), "secret") {
+			t.Errorf("%v: the error contains the token: %v", token, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The reasons why an incoming request is not authorized. They could be compared with errors.Is against the errors
+// which are returned by the validation functions.
+var (
+	ErrMalformedHeader      = errors.New("The authorization header is malformed")
+	ErrKeyFetchFailed       = errors.New("The signing keys could not be fetched")
+	ErrUnsupportedAlgorithm = errors.New("The signing algorithm is not supported")
+	ErrWrongIssuer          = errors.New("The token has a wrong issuer")
+	ErrWrongAudience        = errors.New("The token has a wrong audience")
+	ErrTokenExpired         = errors.New("The token is expired")
+	ErrTokenNotYetValid     = errors.New("The token is not valid yet")
+	ErrUnknownKeyId         = errors.New("The signing key of the token is unknown")
+	ErrMalformedKey         = errors.New("The signing key of the token is malformed")
+	ErrBadSignature         = errors.New("The signature of the token is not valid")
+	ErrMissingEndorsement   = errors.New("The signing key is not endorsed for the channel")
+	ErrServiceUrlMismatch   = errors.New("The service url of the token does not match the activity")
+)
+
+// The AuthorizationError describes why an incoming request is not authorized. Its Reason is one of the Err values
+// above so errors.Is(err, skypeapi.ErrTokenExpired) could be used to check for a specific reason.
+type AuthorizationError struct {
+	// One of the Err values which describes the reason.
+	Reason error
+	// A description of the failure with the values which were checked.
+	Detail string
+	// The error which caused the failure, for example the error of a failed fetch of the SigningKeys. It could be nil.
+	Cause error
+}
+
+func (authorizationError *AuthorizationError) Error() string {
+	if authorizationError.Cause != nil {
+		return fmt.Sprintf("%v: %v", authorizationError.Detail, authorizationError.Cause)
+	}
+	return authorizationError.Detail
+}
+
+// Returns true if the target is the Reason of the error.
+func (authorizationError *AuthorizationError) Is(target error) bool {
+	return authorizationError.Reason == target
+}
+
+// Returns the Cause of the error.
+func (authorizationError *AuthorizationError) Unwrap() error {
+	return authorizationError.Cause
+}
+
+func newAuthorizationError(reason error, detailTemplate string, values ...interface{}) *AuthorizationError {
+	return &AuthorizationError{
+		Reason: reason,
+		Detail: fmt.Sprintf(detailTemplate, values...),
+	}
+}
+
+func wrapAuthorizationError(reason error, cause error) *AuthorizationError {
+	return &AuthorizationError{
+		Reason: reason,
+		Detail: reason.Error(),
+		Cause:  cause,
+	}
+}
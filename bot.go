@@ -0,0 +1,163 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+)
+
+const (
+	botAlreadyStartedError = "The bot has already been started"
+)
+
+// The Bot coordinates the lifecycle of an Endpoint server and the background work of its EndpointHandler. Start
+// starts the server, the background refresh of the SigningKeyCache and the AccessTokenManager. Shutdown stops
+// accepting activities, waits for the running handlers and their outgoing replies and stops the background work.
+type Bot struct {
+	// The Endpoint which declares the address, path and TLS configuration of the server.
+	Endpoint *Endpoint
+	// The EndpointHandler which handles the incoming activities.
+	Handler *EndpointHandler
+	// The AccessTokenManager which is refreshed in the background while the bot is running. It could be nil.
+	AccessTokenManager *AccessTokenManager
+	// The certificate and private key files of the server. If both are empty the server does not use TLS
+	// which could be used behind a TLS terminating proxy.
+	CertFile, KeyFile string
+
+	mutex  sync.Mutex
+	server *http.Server
+	done   chan error
+}
+
+// Returns a new Bot which serves the handler on the endpoint with the given certificate and private key files.
+// If the TokenSource of the handler is an AccessTokenManager it is refreshed in the background.
+func NewBot(endpoint *Endpoint, handler *EndpointHandler, certFile, keyFile string) *Bot {
+	accessTokenManager, _ := handler.TokenSource.(*AccessTokenManager)
+	return &Bot{
+		Endpoint:           endpoint,
+		Handler:            handler,
+		AccessTokenManager: accessTokenManager,
+		CertFile:           certFile,
+		KeyFile:            keyFile,
+	}
+}
+
+// Starts the bot. The address of the Endpoint is bound before Start returns so the bot accepts activities
+// afterwards. The ctx bounds the initial fetch of the SigningKeys and the access token which warm up the caches.
+// Errors of this warm up are not returned because the caches retry on demand.
+func (bot *Bot) Start(ctx context.Context) error {
+	bot.mutex.Lock()
+	defer bot.mutex.Unlock()
+	if bot.server != nil {
+		return errors.New(botAlreadyStartedError)
+	}
+	server := bot.Endpoint.SetupServer(*bot.Handler)
+	if bot.CertFile != "" || bot.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(bot.CertFile, bot.KeyFile)
+		if err != nil {
+			return err
+		}
+		if server.TLSConfig == nil {
+			server.TLSConfig = &tls.Config{}
+		} else {
+			server.TLSConfig = server.TLSConfig.Clone()
+		}
+		server.TLSConfig.Certificates = append(server.TLSConfig.Certificates, certificate)
+	}
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+
+	if bot.Handler.SigningKeyCache != nil {
+		bot.Handler.SigningKeyCache.refresh(ctx, bot.Handler.Configuration)
+		bot.Handler.SigningKeyCache.start(bot.Handler.Configuration)
+	}
+	if bot.Handler.EmulatorSigningKeyCache != nil {
+		bot.Handler.EmulatorSigningKeyCache.RefreshWithContext(ctx)
+		bot.Handler.EmulatorSigningKeyCache.Start()
+	}
+	if bot.AccessTokenManager != nil {
+		bot.AccessTokenManager.TokenWithContext(ctx)
+		bot.AccessTokenManager.Start()
+	}
+
+	bot.server = server
+	bot.done = make(chan error, 1)
+	go func(done chan<- error) {
+		if server.TLSConfig != nil && len(server.TLSConfig.Certificates) != 0 {
+			done <- server.ServeTLS(listener, "", "")
+		} else {
+			done <- server.Serve(listener)
+		}
+	}(bot.done)
+	return nil
+}
+
+// Returns a channel which receives the error of the server once it stopped serving. After a Shutdown the
+// error is http.ErrServerClosed. Returns nil if the bot was not started.
+func (bot *Bot) Done() <-chan error {
+	bot.mutex.Lock()
+	defer bot.mutex.Unlock()
+	return bot.done
+}
+
+// Stops the bot gracefully. The server stops accepting requests and waits for the running requests and their
+// synchronous handlers. Afterwards the activities which are queued at the Dispatcher are handled. Finally the
+// background refresh of the SigningKeyCache and the AccessTokenManager is stopped. If the ctx is done before,
+// the server is closed which cancels the context of the running handlers and the error of the ctx is returned.
+// Handlers which ignore their context could still be running afterwards.
+func (bot *Bot) Shutdown(ctx context.Context) error {
+	bot.mutex.Lock()
+	server := bot.server
+	bot.mutex.Unlock()
+	if server == nil {
+		return nil
+	}
+	err := server.Shutdown(ctx)
+	if err != nil {
+		// closes the remaining connections which cancels the context of their requests
+		server.Close()
+	}
+	if bot.Handler.Dispatcher != nil {
+		if drainErr := bot.Handler.Dispatcher.Drain(ctx); err == nil {
+			err = drainErr
+		}
+	}
+	if bot.Handler.SigningKeyCache != nil {
+		bot.Handler.SigningKeyCache.Stop()
+	}
+	if bot.Handler.EmulatorSigningKeyCache != nil {
+		bot.Handler.EmulatorSigningKeyCache.Stop()
+	}
+	if bot.AccessTokenManager != nil {
+		bot.AccessTokenManager.Stop()
+	}
+	return err
+}
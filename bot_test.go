@@ -0,0 +1,81 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBotShutdownCancelsRunningHandlers(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	started := make(chan struct{})
+	canceled := make(chan bool, 1)
+	handler := NewTurnEndpointHandler(func(turn *TurnContext) {
+		close(started)
+		select {
+		case <-turn.Context.Done():
+			canceled <- true
+		case <-time.After(5 * time.Second):
+			canceled <- false
+		}
+	}, StaticTokenSource(""), "")
+	handler.AllowUnauthenticated = true
+	handler.SigningKeyCache = nil
+	handler.TlsHeaderValue = ""
+	bot := NewBot(NewEndpoint(address), handler, "", "")
+	if err := bot.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	go http.Post("http://"+address+"/", "application/json", strings.NewReader(`{"type":"message","text":"hello"}`))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := bot.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, expected %v", err, context.DeadlineExceeded)
+	}
+	select {
+	case ok := <-canceled:
+		if !ok {
+			t.Error("the context of the running handler was not canceled")
+		}
+	case <-time.After(time.Second):
+		t.Error("the context of the running handler was not canceled after the shutdown")
+	}
+	if err := <-bot.Done(); err != http.ErrServerClosed {
+		t.Errorf("got server error %v, expected %v", err, http.ErrServerClosed)
+	}
+}
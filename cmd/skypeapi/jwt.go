@@ -0,0 +1,129 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/michivip/skypeapi"
+)
+
+func runJwt(arguments []string) error {
+	if len(arguments) == 0 {
+		return fmt.Errorf("Usage: skypeapi jwt decode|verify [flags] [TOKEN]")
+	}
+	switch arguments[0] {
+	case "decode":
+		return runJwtDecode(arguments[1:])
+	case "verify":
+		return runJwtVerify(arguments[1:])
+	default:
+		return fmt.Errorf("Unknown jwt command %q, expected decode or verify", arguments[0])
+	}
+}
+
+// Prints the header and the payload of the token without verifying it.
+func runJwtDecode(arguments []string) error {
+	flagSet := newFlagSet("jwt decode")
+	flagSet.Parse(arguments)
+	microsoftJsonWebToken, err := parseToken(flagSet.Args())
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(microsoftJsonWebToken.Header); err != nil {
+		return err
+	} else if err := encoder.Encode(microsoftJsonWebToken.Payload); err != nil {
+		return err
+	}
+	printTimes(microsoftJsonWebToken.Payload)
+	return nil
+}
+
+// Verifies the token against the signing keys of a JWKS file or url. Without a JWKS the keys are fetched from the
+// OpenID metadata of the Bot Connector service.
+func runJwtVerify(arguments []string) error {
+	flagSet := newFlagSet("jwt verify")
+	var credentials credentials
+	credentials.register(flagSet)
+	jwks := flagSet.String("jwks", "", "the file or url of the JSON web key set (default the keys of the Bot Connector service)")
+	issuer := flagSet.String("issuer", skypeapi.DefaultConfiguration.Issuer, "the expected issuer of the token")
+	flagSet.Parse(arguments)
+	if credentials.appId == "" {
+		return fmt.Errorf("The app id is required")
+	}
+	microsoftJsonWebToken, err := parseToken(flagSet.Args())
+	if err != nil {
+		return err
+	}
+	signingKeys, err := loadSigningKeys(*jwks)
+	if err != nil {
+		return err
+	}
+	configuration := skypeapi.NewConfiguration()
+	configuration.Issuer = *issuer
+	if err := microsoftJsonWebToken.VerifyWithConfiguration(configuration, credentials.appId, signingKeys); err != nil {
+		return err
+	}
+	fmt.Println("The token is valid")
+	printTimes(microsoftJsonWebToken.Payload)
+	return nil
+}
+
+func parseToken(arguments []string) (skypeapi.MicrosoftJsonWebToken, error) {
+	token, err := readToken(arguments)
+	if err != nil {
+		return skypeapi.MicrosoftJsonWebToken{}, err
+	}
+	return skypeapi.ParseMicrosoftJsonWebToken(token)
+}
+
+func loadSigningKeys(jwks string) (skypeapi.SigningKeys, error) {
+	if jwks == "" {
+		return skypeapi.GetSigningKeys()
+	} else if strings.HasPrefix(jwks, "https://") || strings.HasPrefix(jwks, "http://") {
+		return skypeapi.GetSigningKeysByUrl(jwks)
+	}
+	file, err := os.Open(jwks)
+	if err != nil {
+		return skypeapi.SigningKeys{}, err
+	}
+	defer file.Close()
+	var signingKeys skypeapi.SigningKeys
+	err = json.NewDecoder(file).Decode(&signingKeys)
+	return signingKeys, err
+}
+
+func printTimes(payload skypeapi.JwtPayload) {
+	if payload.CreatedOnNbf != 0 {
+		fmt.Fprintf(os.Stderr, "Not before %v\n", time.Unix(int64(payload.CreatedOnNbf), 0).Format(time.RFC3339))
+	}
+	expires := time.Unix(int64(payload.Expires), 0)
+	fmt.Fprintf(os.Stderr, "Expires at %v (in %v)\n", expires.Format(time.RFC3339), time.Until(expires).Round(time.Second))
+}
@@ -0,0 +1,133 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+// Command skypeapi wraps the skypeapi package to request access tokens, inspect and verify the tokens of incoming
+// requests, send activities to conversations and run a simple bot.
+//
+// Usage:
+//
+//	skypeapi token -app-id ID -app-password PASSWORD
+//	skypeapi jwt decode [TOKEN]
+//	skypeapi jwt verify -app-id ID [-jwks FILE|URL] [-issuer ISSUER] [TOKEN]
+//	skypeapi send -service-url URL -conversation ID [-reply-to ID] (-text TEXT | -json FILE)
+//	skypeapi serve -addr :8080 -app-id ID -app-password PASSWORD [-cert FILE -key FILE] [-echo]
+//
+// The app id and password could be set with the environment variables SKYPEAPI_APP_ID and SKYPEAPI_APP_PASSWORD
+// as well. Tokens which are not passed as argument are read from the standard input.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	appIdEnvironmentVariable       = "SKYPEAPI_APP_ID"
+	appPasswordEnvironmentVariable = "SKYPEAPI_APP_PASSWORD"
+
+	usage = `Usage: skypeapi <command> [flags]
+
+Commands:
+  token        requests an access token and prints it with its expiry
+  jwt decode   prints the header and payload of a token
+  jwt verify   verifies a token against the signing keys
+  send         sends a text or JSON activity to a conversation
+  serve        runs a bot which prints and optionally echoes the incoming activities
+
+Run "skypeapi <command> -h" for the flags of a command.
+`
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch command, arguments := os.Args[1], os.Args[2:]; command {
+	case "token":
+		err = runToken(arguments)
+	case "jwt":
+		err = runJwt(arguments)
+	case "send":
+		err = runSend(arguments)
+	case "serve":
+		err = runServe(arguments)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%v", command, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// The credentials of the bot which are shared by the commands.
+type credentials struct {
+	appId, appPassword string
+}
+
+// Registers the credential flags. Their defaults are taken from the environment.
+func (credentials *credentials) register(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&credentials.appId, "app-id", os.Getenv(appIdEnvironmentVariable),
+		"the microsoft app id of the bot (default $"+appIdEnvironmentVariable+")")
+	flagSet.StringVar(&credentials.appPassword, "app-password", os.Getenv(appPasswordEnvironmentVariable),
+		"the microsoft app password of the bot (default $"+appPasswordEnvironmentVariable+")")
+}
+
+func (credentials *credentials) require() error {
+	if credentials.appId == "" || credentials.appPassword == "" {
+		return fmt.Errorf("The app id and the app password are required")
+	}
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("skypeapi "+name, flag.ExitOnError)
+}
+
+// Returns the token of the first argument or reads it from the standard input. A "Bearer " prefix is kept so
+// the value could be parsed as authorization header.
+func readToken(arguments []string) (string, error) {
+	var token string
+	if len(arguments) > 0 && arguments[0] != "-" {
+		token = arguments[0]
+	} else {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", err
+		}
+		token = string(input)
+	}
+	token = strings.TrimSpace(token)
+	if !strings.HasPrefix(token, "Bearer ") {
+		token = "Bearer " + token
+	}
+	return token, nil
+}
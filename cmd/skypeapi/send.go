@@ -0,0 +1,98 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/michivip/skypeapi"
+)
+
+// Sends a text message or the activity of a JSON file to a conversation and prints the id of the sent activity.
+func runSend(arguments []string) error {
+	flagSet := newFlagSet("send")
+	var credentials credentials
+	credentials.register(flagSet)
+	token := flagSet.String("token", "", "the access token which is used instead of requesting one with the app credentials")
+	serviceUrl := flagSet.String("service-url", "", "the service url of the channel")
+	conversationId := flagSet.String("conversation", "", "the id of the conversation")
+	replyTo := flagSet.String("reply-to", "", "the id of the activity to reply to")
+	text := flagSet.String("text", "", "the text of the message")
+	jsonFile := flagSet.String("json", "", `the file of the JSON activity or "-" for the standard input`)
+	flagSet.Parse(arguments)
+	if *serviceUrl == "" || *conversationId == "" {
+		return fmt.Errorf("The service url and the conversation are required")
+	} else if (*text == "") == (*jsonFile == "") {
+		return fmt.Errorf("Either a text or a JSON activity is required")
+	}
+
+	activity := &skypeapi.Activity{Type: skypeapi.MessageActivityType, Text: *text}
+	if *jsonFile != "" {
+		var err error
+		if activity, err = readActivity(*jsonFile); err != nil {
+			return err
+		}
+	}
+	var tokenSource skypeapi.TokenSource
+	if *token != "" {
+		tokenSource = skypeapi.StaticTokenSource(*token)
+	} else if err := credentials.require(); err != nil {
+		return err
+	} else {
+		tokenSource = skypeapi.NewAccessTokenManager(credentials.appId, credentials.appPassword)
+	}
+
+	replyUrl := fmt.Sprintf("%v/v3/conversations/%v/activities", strings.TrimSuffix(*serviceUrl, "/"),
+		url.PathEscape(*conversationId))
+	if *replyTo != "" {
+		replyUrl += "/" + url.PathEscape(*replyTo)
+		activity.ReplyToID = *replyTo
+	}
+	// the service url is given explicitly so the credentials could be sent to it
+	skypeapi.DefaultConfiguration.TrustedServiceUrls.Trust(*serviceUrl)
+	resourceResponse, err := skypeapi.SendActivityRequest(activity, replyUrl, tokenSource)
+	if err != nil {
+		return err
+	}
+	fmt.Println(resourceResponse.ID)
+	return nil
+}
+
+func readActivity(fileName string) (*skypeapi.Activity, error) {
+	file := os.Stdin
+	if fileName != "-" {
+		var err error
+		if file, err = os.Open(fileName); err != nil {
+			return nil, err
+		}
+		defer file.Close()
+	}
+	activity := &skypeapi.Activity{}
+	err := json.NewDecoder(file).Decode(activity)
+	return activity, err
+}
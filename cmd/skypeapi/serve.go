@@ -0,0 +1,109 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/michivip/skypeapi"
+)
+
+// The time the running handlers get to finish after an interrupt.
+const shutdownTimeout = 30 * time.Second
+
+// Runs a bot which prints every incoming activity as JSON and optionally replies with the text of messages.
+func runServe(arguments []string) error {
+	flagSet := newFlagSet("serve")
+	var credentials credentials
+	credentials.register(flagSet)
+	address := flagSet.String("addr", ":8080", "the address the server listens on")
+	path := flagSet.String("path", "/", "the path the activities are posted to")
+	certFile := flagSet.String("cert", "", "the certificate file of the server (default plain HTTP)")
+	keyFile := flagSet.String("key", "", "the private key file of the server")
+	echo := flagSet.Bool("echo", false, "reply to messages with their text")
+	emulator := flagSet.Bool("emulator", false, "accept the tokens of the Bot Framework Emulator")
+	insecure := flagSet.Bool("insecure", false, "accept unauthenticated requests if no app id is set")
+	flagSet.Parse(arguments)
+	if credentials.appId == "" && !*insecure {
+		return fmt.Errorf("The app id is required unless -insecure is set")
+	}
+
+	var tokenSource skypeapi.TokenSource = skypeapi.StaticTokenSource("")
+	if credentials.appPassword != "" {
+		tokenSource = skypeapi.NewAccessTokenManager(credentials.appId, credentials.appPassword)
+	}
+	handler := skypeapi.NewTurnEndpointHandler(func(turn *skypeapi.TurnContext) {
+		printActivity(turn.Activity)
+		if *echo && turn.Activity.IsMessage() && turn.Activity.Text != "" {
+			if _, err := turn.Reply(turn.Activity.Text); err != nil {
+				log.Printf("The reply could not be sent: %v", err)
+			}
+		}
+	}, tokenSource, credentials.appId)
+	handler.AcceptEmulatorTokens = *emulator
+	handler.AllowUnauthenticated = *insecure
+	handler.AuthorizationFailedHandleFunction = func(req *http.Request, err error) {
+		log.Printf("Rejected request of %v: %v", req.RemoteAddr, err)
+	}
+	if *emulator {
+		handler.EmulatorSigningKeyCache = skypeapi.NewEmulatorSigningKeyCache(nil)
+	}
+	if *certFile == "" {
+		// the default TLS configuration of the endpoint is not used without a certificate
+		handler.TlsHeaderValue = ""
+	}
+
+	endpoint := skypeapi.NewEndpoint(*address)
+	endpoint.Path = *path
+	bot := skypeapi.NewBot(endpoint, handler, *certFile, *keyFile)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := bot.Start(ctx); err != nil {
+		return err
+	}
+	log.Printf("Listening on %v%v", *address, *path)
+
+	select {
+	case err := <-bot.Done():
+		return err
+	case <-ctx.Done():
+	}
+	log.Print("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return bot.Shutdown(shutdownCtx)
+}
+
+func printActivity(activity *skypeapi.Activity) {
+	jsonEncoded, _ := json.MarshalIndent(activity, "", "  ")
+	fmt.Println(string(jsonEncoded))
+}
@@ -0,0 +1,61 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/michivip/skypeapi"
+)
+
+// Requests an access token and prints it to the standard output. The expiry is printed to the standard error so
+// the output could be used in scripts.
+func runToken(arguments []string) error {
+	flagSet := newFlagSet("token")
+	var credentials credentials
+	credentials.register(flagSet)
+	printJson := flagSet.Bool("json", false, "print the complete token response as JSON")
+	flagSet.Parse(arguments)
+	if err := credentials.require(); err != nil {
+		return err
+	}
+
+	requestedAt := time.Now()
+	tokenResponse, err := skypeapi.RequestAccessToken(credentials.appId, credentials.appPassword)
+	if err != nil {
+		return err
+	}
+	if *printJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(tokenResponse)
+	}
+	expiresIn := time.Duration(tokenResponse.ExpiresIn) * time.Second
+	fmt.Println(tokenResponse.AccessToken)
+	fmt.Fprintf(os.Stderr, "Expires at %v (in %v)\n", requestedAt.Add(expiresIn).Format(time.RFC3339), expiresIn)
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// The default timeout of the http.Client of a new Configuration.
+	defaultHttpTimeout = 30 * time.Second
+)
+
+// The Configuration declares the microsoft service endpoints which are used to request access tokens and to
+// authorize incoming requests. It could be used to run the library against a sovereign cloud or a local test server.
+// Its http.Client is used for every request of the library so proxies, timeouts and custom transports only need
+// to be set up once.
+type Configuration struct {
+	// The url which is used to request access tokens.
+	TokenUrl string
+	// The scope of the requested access tokens.
+	TokenScope string
+	// The url of the OpenID metadata document which references the SigningKeys.
+	OpenIdMetadataUrl string
+	// The issuer which is expected in the tokens of incoming requests.
+	Issuer string
+	// The http.Client which is used to send the requests. If it is nil the http.DefaultClient is used.
+	HttpClient *http.Client
+	// The RetryPolicy which is used for token requests and outgoing activities. If it is nil failed requests
+	// are not retried.
+	RetryPolicy *RetryPolicy
+	// The service urls outgoing activities could be sent to. The EndpointHandler trusts the service url of every
+	// authorized activity. If it is nil the credentials are sent to every url.
+	TrustedServiceUrls *ServiceUrlRegistry
+	// The url of the OpenID metadata document which references the keys of the Bot Framework Emulator.
+	EmulatorOpenIdMetadataUrl string
+	// The issuers which are accepted in the tokens of the Bot Framework Emulator.
+	EmulatorIssuers []string
+}
+
+// The Configuration which is used by the package level functions and if no other Configuration is set.
+var DefaultConfiguration = NewConfiguration()
+
+// Returns a new Configuration with the endpoints of the microsoft public cloud, a http.Client with a timeout of
+// 30 seconds, an empty ServiceUrlRegistry and the endpoints of the Bot Framework Emulator.
+func NewConfiguration() *Configuration {
+	return &Configuration{
+		TokenUrl:           requestTokenUrl,
+		TokenScope:         requestTokenScope,
+		OpenIdMetadataUrl:  openIdRequestPath,
+		Issuer:             issuerUrl,
+		HttpClient:         &http.Client{Timeout: defaultHttpTimeout},
+		TrustedServiceUrls: NewServiceUrlRegistry(),
+
+		EmulatorOpenIdMetadataUrl: emulatorOpenIdMetadataUrl,
+		EmulatorIssuers:           append([]string(nil), emulatorIssuers...),
+	}
+}
+
+func (configuration *Configuration) httpClient() *http.Client {
+	if configuration.HttpClient == nil {
+		return http.DefaultClient
+	}
+	return configuration.HttpClient
+}
+
+// Returns the configuration or the DefaultConfiguration if it is nil.
+func configurationOrDefault(configuration *Configuration) *Configuration {
+	if configuration == nil {
+		return DefaultConfiguration
+	}
+	return configuration
+}
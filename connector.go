@@ -0,0 +1,231 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	conversationsTemplate       = "%v/v3/conversations"
+	conversationTemplate        = "%v/v3/conversations/%v/activities"
+	activityTemplate            = "%v/v3/conversations/%v/activities/%v"
+	conversationMembersTemplate = "%v/v3/conversations/%v/members"
+	activityMembersTemplate     = "%v/v3/conversations/%v/activities/%v/members"
+	attachmentsTemplate         = "%v/v3/conversations/%v/attachments"
+)
+
+// The ConnectorClient sends requests to the conversations api of the Bot Connector service at the given ServiceUrl.
+// The ServiceUrl has to be trusted by the TrustedServiceUrls of the Configuration. The EndpointHandler trusts the
+// service urls of incoming activities, others like the ones of proactive messages have to be trusted explicitly.
+// For details see: https://docs.microsoft.com/en-us/bot-framework/rest-api/bot-framework-rest-connector-api-reference
+type ConnectorClient struct {
+	// The service url of the channel. It is usually taken from Activity.ServiceURL.
+	ServiceUrl string
+	// The TokenSource which supplies the bearer token which is used to authorize the requests.
+	TokenSource TokenSource
+	// The Configuration which provides the http.Client. If it is nil the DefaultConfiguration is used.
+	Configuration *Configuration
+}
+
+// Returns a new ConnectorClient which sends its requests to the given serviceUrl.
+func NewConnectorClient(serviceUrl string, tokenSource TokenSource) *ConnectorClient {
+	return &ConnectorClient{
+		ServiceUrl:  serviceUrl,
+		TokenSource: tokenSource,
+	}
+}
+
+// Creates a new conversation. The bot needs to be a member of the conversation.
+func (connectorClient *ConnectorClient) CreateConversation(parameters *ConversationParameters) (ConversationResourceResponse, error) {
+	return connectorClient.CreateConversationWithContext(context.Background(), parameters)
+}
+
+// Creates a new conversation like CreateConversation. The request is canceled if the ctx is done.
+func (connectorClient *ConnectorClient) CreateConversationWithContext(ctx context.Context, parameters *ConversationParameters) (ConversationResourceResponse, error) {
+	var conversationResourceResponse ConversationResourceResponse
+	err := connectorClient.send(ctx, http.MethodPost, connectorClient.url(conversationsTemplate), parameters, &conversationResourceResponse)
+	return conversationResourceResponse, err
+}
+
+// Sends the activity to the end of the conversation. This could be used to start proactive messages.
+// Returns the ResourceResponse which contains the ID the channel assigned to the activity.
+func (connectorClient *ConnectorClient) SendToConversation(conversationId string, activity *Activity) (ResourceResponse, error) {
+	return connectorClient.SendToConversationWithContext(context.Background(), conversationId, activity)
+}
+
+// Sends the activity to the end of the conversation like SendToConversation. The request is canceled if the ctx is done.
+func (connectorClient *ConnectorClient) SendToConversationWithContext(ctx context.Context, conversationId string, activity *Activity) (ResourceResponse, error) {
+	var resourceResponse ResourceResponse
+	err := connectorClient.send(ctx, http.MethodPost, connectorClient.url(conversationTemplate, conversationId), activity, &resourceResponse)
+	return resourceResponse, err
+}
+
+// Sends the activity as a reply to the activity with the given activityId.
+// Returns the ResourceResponse which contains the ID the channel assigned to the reply.
+func (connectorClient *ConnectorClient) ReplyToActivity(conversationId, activityId string, activity *Activity) (ResourceResponse, error) {
+	return connectorClient.ReplyToActivityWithContext(context.Background(), conversationId, activityId, activity)
+}
+
+// Sends the activity as a reply like ReplyToActivity. The request is canceled if the ctx is done.
+func (connectorClient *ConnectorClient) ReplyToActivityWithContext(ctx context.Context, conversationId, activityId string, activity *Activity) (ResourceResponse, error) {
+	var resourceResponse ResourceResponse
+	err := connectorClient.send(ctx, http.MethodPost, connectorClient.url(activityTemplate, conversationId, activityId), activity, &resourceResponse)
+	return resourceResponse, err
+}
+
+// Replaces the activity with the given activityId. Not all channels support editing of sent activities.
+// Returns the ResourceResponse which contains the ID of the updated activity.
+func (connectorClient *ConnectorClient) UpdateActivity(conversationId, activityId string, activity *Activity) (ResourceResponse, error) {
+	return connectorClient.UpdateActivityWithContext(context.Background(), conversationId, activityId, activity)
+}
+
+// Replaces the activity like UpdateActivity. The request is canceled if the ctx is done.
+func (connectorClient *ConnectorClient) UpdateActivityWithContext(ctx context.Context, conversationId, activityId string, activity *Activity) (ResourceResponse, error) {
+	var resourceResponse ResourceResponse
+	err := connectorClient.send(ctx, http.MethodPut, connectorClient.url(activityTemplate, conversationId, activityId), activity, &resourceResponse)
+	return resourceResponse, err
+}
+
+// Deletes the activity with the given activityId. Not all channels support deleting of sent activities.
+func (connectorClient *ConnectorClient) DeleteActivity(conversationId, activityId string) error {
+	return connectorClient.DeleteActivityWithContext(context.Background(), conversationId, activityId)
+}
+
+// Deletes the activity like DeleteActivity. The request is canceled if the ctx is done.
+func (connectorClient *ConnectorClient) DeleteActivityWithContext(ctx context.Context, conversationId, activityId string) error {
+	return connectorClient.send(ctx, http.MethodDelete, connectorClient.url(activityTemplate, conversationId, activityId), nil, nil)
+}
+
+// Returns the members of the conversation.
+func (connectorClient *ConnectorClient) GetConversationMembers(conversationId string) ([]ChannelAccount, error) {
+	return connectorClient.GetConversationMembersWithContext(context.Background(), conversationId)
+}
+
+// Returns the members of the conversation like GetConversationMembers. The request is canceled if the ctx is done.
+func (connectorClient *ConnectorClient) GetConversationMembersWithContext(ctx context.Context, conversationId string) ([]ChannelAccount, error) {
+	var members []ChannelAccount
+	err := connectorClient.send(ctx, http.MethodGet, connectorClient.url(conversationMembersTemplate, conversationId), nil, &members)
+	return members, err
+}
+
+// Returns the members of the activity with the given activityId.
+func (connectorClient *ConnectorClient) GetActivityMembers(conversationId, activityId string) ([]ChannelAccount, error) {
+	return connectorClient.GetActivityMembersWithContext(context.Background(), conversationId, activityId)
+}
+
+// Returns the members of the activity like GetActivityMembers. The request is canceled if the ctx is done.
+func (connectorClient *ConnectorClient) GetActivityMembersWithContext(ctx context.Context, conversationId, activityId string) ([]ChannelAccount, error) {
+	var members []ChannelAccount
+	err := connectorClient.send(ctx, http.MethodGet, connectorClient.url(activityMembersTemplate, conversationId, activityId), nil, &members)
+	return members, err
+}
+
+// Uploads the attachment to the storage of the channel. The returned ResourceResponse contains the ID of the
+// uploaded attachment.
+func (connectorClient *ConnectorClient) UploadAttachment(conversationId string, attachmentData *AttachmentData) (ResourceResponse, error) {
+	return connectorClient.UploadAttachmentWithContext(context.Background(), conversationId, attachmentData)
+}
+
+// Uploads the attachment like UploadAttachment. The request is canceled if the ctx is done.
+func (connectorClient *ConnectorClient) UploadAttachmentWithContext(ctx context.Context, conversationId string, attachmentData *AttachmentData) (ResourceResponse, error) {
+	var resourceResponse ResourceResponse
+	err := connectorClient.send(ctx, http.MethodPost, connectorClient.url(attachmentsTemplate, conversationId), attachmentData, &resourceResponse)
+	return resourceResponse, err
+}
+
+// Builds the request url from the template. The ServiceUrl is the first argument and the other
+// arguments are escaped as path segments.
+func (connectorClient *ConnectorClient) url(template string, pathSegments ...string) string {
+	arguments := []interface{}{strings.TrimSuffix(connectorClient.ServiceUrl, "/")}
+	for _, pathSegment := range pathSegments {
+		arguments = append(arguments, url.PathEscape(pathSegment))
+	}
+	return fmt.Sprintf(template, arguments...)
+}
+
+func (connectorClient *ConnectorClient) send(ctx context.Context, method, requestUrl string, body, result interface{}) error {
+	configuration := configurationOrDefault(connectorClient.Configuration)
+	return sendJsonRequest(ctx, configuration, connectorClient.TokenSource, method, requestUrl, body, result)
+}
+
+// Sends the body json encoded to the requestUrl and decodes the response into the result. The body and the
+// result could be nil. The request is sent with the http.Client of the configuration and retried according to its
+// RetryPolicy. The credentials are only sent if the requestUrl is trusted by its TrustedServiceUrls.
+func sendJsonRequest(ctx context.Context, configuration *Configuration, tokenSource TokenSource, method, requestUrl string, body, result interface{}) error {
+	if err := configuration.TrustedServiceUrls.check(requestUrl); err != nil {
+		return err
+	}
+	var jsonEncoded []byte
+	if body != nil {
+		var err error
+		if jsonEncoded, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	// only posted activities could be delivered twice if they are sent again
+	idempotent := method != http.MethodPost
+	resp, err := sendWithRetry(ctx, configuration.httpClient(), configuration.RetryPolicy, idempotent, func() (*http.Request, error) {
+		var requestBody io.Reader
+		if body != nil {
+			requestBody = bytes.NewReader(jsonEncoded)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, requestUrl, requestBody)
+		if err != nil {
+			return nil, err
+		}
+		// the token is requested for every attempt because it could have been refreshed in the meantime
+		if authorizationToken, err := tokenFromSource(ctx, tokenSource); err != nil {
+			return nil, err
+		} else {
+			req.Header.Set(authorizationHeaderKey, authorizationHeaderValuePrefix+authorizationToken)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var statusCode int = resp.StatusCode
+	if statusCode != http.StatusOK && statusCode != http.StatusCreated &&
+		statusCode != http.StatusAccepted && statusCode != http.StatusNoContent {
+		return newConnectorError(resp, requestUrl)
+	}
+	if result != nil && statusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	defaultActivityIdStoreCapacity = 10000
+	defaultActivityIdStoreTtl      = 10 * time.Minute
+)
+
+// An ActivityIdStore remembers the keys of handled activities so activities which are delivered again by the
+// channel could be detected. Implementations have to be safe for concurrent use.
+type ActivityIdStore interface {
+	// Stores the key and returns true if it was already stored before.
+	Seen(key string) bool
+}
+
+// The MemoryActivityIdStore is an ActivityIdStore which keeps the keys in memory. It stores at most Capacity keys
+// and forgets the least recently stored keys first. Keys expire after the TTL.
+type MemoryActivityIdStore struct {
+	capacity int
+	ttl      time.Duration
+	mutex    sync.Mutex
+	elements map[string]*list.Element
+	order    *list.List
+}
+
+type activityIdEntry struct {
+	key      string
+	storedAt time.Time
+}
+
+// Returns a new MemoryActivityIdStore which stores up to capacity keys for the duration of the ttl.
+func NewMemoryActivityIdStore(capacity int, ttl time.Duration) *MemoryActivityIdStore {
+	return &MemoryActivityIdStore{
+		capacity: capacity,
+		ttl:      ttl,
+		elements: make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (memoryActivityIdStore *MemoryActivityIdStore) Seen(key string) bool {
+	memoryActivityIdStore.mutex.Lock()
+	defer memoryActivityIdStore.mutex.Unlock()
+	now := time.Now()
+	// the entries are ordered by their age so the expired ones are at the back of the list
+	for element := memoryActivityIdStore.order.Back(); element != nil; element = memoryActivityIdStore.order.Back() {
+		if now.Sub(element.Value.(*activityIdEntry).storedAt) < memoryActivityIdStore.ttl {
+			break
+		}
+		memoryActivityIdStore.remove(element)
+	}
+	if _, ok := memoryActivityIdStore.elements[key]; ok {
+		return true
+	}
+	for memoryActivityIdStore.order.Len() >= memoryActivityIdStore.capacity && memoryActivityIdStore.order.Len() != 0 {
+		memoryActivityIdStore.remove(memoryActivityIdStore.order.Back())
+	}
+	memoryActivityIdStore.elements[key] = memoryActivityIdStore.order.PushFront(&activityIdEntry{key: key, storedAt: now})
+	return false
+}
+
+func (memoryActivityIdStore *MemoryActivityIdStore) remove(element *list.Element) {
+	memoryActivityIdStore.order.Remove(element)
+	delete(memoryActivityIdStore.elements, element.Value.(*activityIdEntry).key)
+}
+
+// Returns a Middleware which drops activities which were already handled. Activities are identified by their
+// channel, conversation and ID. Activities without an ID are always passed on. If the store is nil a
+// MemoryActivityIdStore with 10000 keys and a TTL of ten minutes is used.
+func DeduplicationMiddleware(store ActivityIdStore) Middleware {
+	if store == nil {
+		store = NewMemoryActivityIdStore(defaultActivityIdStoreCapacity, defaultActivityIdStoreTtl)
+	}
+	return func(activity *Activity, next func(activity *Activity)) {
+		if activity.ID == "" || !store.Seen(activityKey(activity)) {
+			next(activity)
+		}
+	}
+}
+
+// Returns the key which identifies the activity across channels and conversations.
+func activityKey(activity *Activity) string {
+	return activity.ChannelID + "/" + activity.conversationId() + "/" + activity.ID
+}
@@ -0,0 +1,179 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"context"
+	"hash/fnv"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	dispatcherPanicLogTemplate = "Recovered from panic in activity dispatcher: %v\n%s"
+)
+
+// The ActivityDispatcher processes incoming activities asynchronously with a fixed number of workers. Every worker
+// has its own bounded queue and all activities of a conversation are queued at the same worker so they are
+// handled in the order they arrived. If the queue of a worker is full the activity is rejected and the
+// EndpointHandler answers with 503 Service Unavailable so the channel sends it again later.
+type ActivityDispatcher struct {
+	queues []chan func(ctx context.Context)
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mutex   sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+
+	inFlight  int64
+	processed uint64
+	rejected  uint64
+}
+
+// The DispatcherStats describe the current load of an ActivityDispatcher.
+type DispatcherStats struct {
+	// The number of workers.
+	Workers int
+	// The number of activities which could be queued in total.
+	QueueCapacity int
+	// The number of activities which are queued but not handled yet.
+	QueueDepth int
+	// The number of activities which are handled right now.
+	InFlight int
+	// The number of activities which were handled since the dispatcher was created.
+	Processed uint64
+	// The number of activities which were rejected because the queue was full or the dispatcher was drained.
+	Rejected uint64
+}
+
+// Returns a new ActivityDispatcher with the given number of workers which are started immediately. Every worker
+// queues up to queueSize activities.
+func NewActivityDispatcher(workers, queueSize int) *ActivityDispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	activityDispatcher := &ActivityDispatcher{
+		queues: make([]chan func(ctx context.Context), workers),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	for index := range activityDispatcher.queues {
+		queue := make(chan func(ctx context.Context), queueSize)
+		activityDispatcher.queues[index] = queue
+		activityDispatcher.workers.Add(1)
+		go activityDispatcher.work(queue)
+	}
+	return activityDispatcher
+}
+
+// Returns the current stats of the dispatcher.
+func (activityDispatcher *ActivityDispatcher) Stats() DispatcherStats {
+	stats := DispatcherStats{
+		Workers:   len(activityDispatcher.queues),
+		InFlight:  int(atomic.LoadInt64(&activityDispatcher.inFlight)),
+		Processed: atomic.LoadUint64(&activityDispatcher.processed),
+		Rejected:  atomic.LoadUint64(&activityDispatcher.rejected),
+	}
+	for _, queue := range activityDispatcher.queues {
+		stats.QueueCapacity += cap(queue)
+		stats.QueueDepth += len(queue)
+	}
+	return stats
+}
+
+// Stops accepting new activities and waits until all queued activities are handled. If the ctx is done first
+// the context of the running handlers is canceled and the error of the ctx is returned.
+func (activityDispatcher *ActivityDispatcher) Drain(ctx context.Context) error {
+	activityDispatcher.mutex.Lock()
+	if !activityDispatcher.closed {
+		activityDispatcher.closed = true
+		for _, queue := range activityDispatcher.queues {
+			close(queue)
+		}
+	}
+	activityDispatcher.mutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		activityDispatcher.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		activityDispatcher.cancel()
+		return nil
+	case <-ctx.Done():
+		activityDispatcher.cancel()
+		return ctx.Err()
+	}
+}
+
+// Queues the job at the worker of the key. Returns false if the queue is full or the dispatcher was drained.
+func (activityDispatcher *ActivityDispatcher) dispatch(key string, job func(ctx context.Context)) bool {
+	activityDispatcher.mutex.RLock()
+	defer activityDispatcher.mutex.RUnlock()
+	if !activityDispatcher.closed {
+		select {
+		case activityDispatcher.queues[activityDispatcher.worker(key)] <- job:
+			return true
+		default:
+		}
+	}
+	atomic.AddUint64(&activityDispatcher.rejected, 1)
+	return false
+}
+
+func (activityDispatcher *ActivityDispatcher) worker(key string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return int(hash.Sum32() % uint32(len(activityDispatcher.queues)))
+}
+
+func (activityDispatcher *ActivityDispatcher) work(queue <-chan func(ctx context.Context)) {
+	defer activityDispatcher.workers.Done()
+	for job := range queue {
+		atomic.AddInt64(&activityDispatcher.inFlight, 1)
+		activityDispatcher.run(job)
+		atomic.AddInt64(&activityDispatcher.inFlight, -1)
+		atomic.AddUint64(&activityDispatcher.processed, 1)
+	}
+}
+
+// Runs the job and recovers from its panics because unlike the http.Server nobody else would recover them.
+func (activityDispatcher *ActivityDispatcher) run(job func(ctx context.Context)) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logPrintf(nil, dispatcherPanicLogTemplate, recovered, debug.Stack())
+		}
+	}()
+	job(activityDispatcher.ctx)
+}
+
+// Returns the key which is used to keep the order of the activities of a conversation.
+func conversationKey(activity *Activity) string {
+	return activity.ChannelID + "/" + activity.conversationId()
+}
@@ -0,0 +1,85 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+const (
+	// The OpenID metadata document which references the keys the tokens of the Bot Framework Emulator are signed with.
+	emulatorOpenIdMetadataUrl = "https://login.microsoftonline.com/botframework.com/v2.0/.well-known/openid-configuration"
+
+	// The version of emulator tokens which carry the app id in the appid claim. Tokens of version 2.0 carry it
+	// in the azp claim.
+	emulatorTokenVersion1 = "1.0"
+
+	wrongAppIdError = "The token was issued for an unexpected app: %v"
+)
+
+// The issuers of the tokens the Bot Framework Emulator sends. It uses the v1 and v2 issuers of two tenants.
+var emulatorIssuers = []string{
+	"https://sts.windows.net/d6d49420-f39b-4df7-a1dc-d59a935871db/",
+	"https://login.microsoftonline.com/d6d49420-f39b-4df7-a1dc-d59a935871db/v2.0",
+	"https://sts.windows.net/f8cdef31-a31e-4b4a-93e4-5f571e91255a/",
+	"https://login.microsoftonline.com/f8cdef31-a31e-4b4a-93e4-5f571e91255a/v2.0",
+}
+
+// Returns a new TokenValidator which accepts the tokens of the Bot Framework Emulator. The issuers are taken from
+// the EmulatorIssuers of the configuration. If the configuration is nil the DefaultConfiguration is used.
+func NewEmulatorTokenValidator(configuration *Configuration) *TokenValidator {
+	tokenValidator := NewTokenValidator(configuration)
+	tokenValidator.Issuers = configurationOrDefault(configuration).EmulatorIssuers
+	return tokenValidator
+}
+
+// Returns a new SigningKeyCache like NewSigningKeyCache which fetches the keys of the Bot Framework Emulator from
+// the EmulatorOpenIdMetadataUrl of the configuration. If the configuration is nil the DefaultConfiguration is used.
+func NewEmulatorSigningKeyCache(configuration *Configuration) *SigningKeyCache {
+	signingKeyCache := NewSigningKeyCache()
+	signingKeyCache.Configuration = configurationOrDefault(configuration).emulatorConfiguration()
+	return signingKeyCache
+}
+
+// Returns true if the token was issued for the Bot Framework Emulator by one of the EmulatorIssuers of the
+// configuration. The signature of the token is not verified.
+func (configuration *Configuration) IsEmulatorToken(microsoftJsonWebToken MicrosoftJsonWebToken) bool {
+	return containsString(configuration.EmulatorIssuers, microsoftJsonWebToken.Payload.Issuer)
+}
+
+// Returns a copy of the configuration which fetches the SigningKeys of the Bot Framework Emulator.
+func (configuration *Configuration) emulatorConfiguration() *Configuration {
+	emulatorConfiguration := *configuration
+	emulatorConfiguration.OpenIdMetadataUrl = configuration.EmulatorOpenIdMetadataUrl
+	return &emulatorConfiguration
+}
+
+// Validates that the token was issued for the app. Tokens of version 1.0 carry the app id in the appid claim,
+// newer ones in the azp claim.
+func validateEmulatorAppId(microsoftJsonWebToken MicrosoftJsonWebToken, microsoftAppId string) error {
+	appId := microsoftJsonWebToken.Payload.AuthorizedParty
+	if microsoftJsonWebToken.Payload.Version == emulatorTokenVersion1 || appId == "" {
+		appId = microsoftJsonWebToken.Payload.AppId
+	}
+	if appId != microsoftAppId {
+		return newAuthorizationError(ErrWrongAudience, wrongAppIdError, appId)
+	}
+	return nil
+}
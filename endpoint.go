@@ -24,6 +24,7 @@ SOFTWARE.
 package skypeapi
 
 import (
+	"context"
 	"net/http"
 	"crypto/tls"
 	"encoding/json"
@@ -70,49 +71,189 @@ func NewEndpoint(address string) (*Endpoint) {
 type EndpointHandler struct {
 	// The MicrosoftAppId is used to authorize incoming requests
 	MicrosoftAppId string
-	// The authorization token which is used to authorize incoming requests
+	// The authorization token which is used to authorize outgoing requests of a TurnContext if no TokenSource is set
 	AuthorizationToken string
+	// The TokenSource which is used to authorize outgoing requests of a TurnContext
+	TokenSource TokenSource
 	// The header value which will be sent to the client with the "Strict-Transport-Security" key
 	TlsHeaderValue string
 	// The function to handle incoming decoded Activity object
 	ActivityReceivedHandleFunction func(activity *Activity)
+	// The function to handle incoming decoded Activity objects wrapped in a TurnContext
+	TurnReceivedHandleFunction func(turn *TurnContext)
+	// The cache which provides the SigningKeys to authorize incoming requests. If it is nil the keys are
+	// fetched on every request.
+	SigningKeyCache *SigningKeyCache
+	// The Configuration which declares the expected issuer and the endpoint of the SigningKeys. If it is nil the
+	// DefaultConfiguration is used. It is used by the SigningKeyCache as well unless the cache has its own.
+	Configuration *Configuration
+	// The middlewares which are called in the given order before the ActivityReceivedHandleFunction and the
+	// TurnReceivedHandleFunction.
+	Middlewares []Middleware
+	// The TokenValidator which validates the tokens of incoming requests. If it is nil a validator with the
+	// issuer of the Configuration and the default clock skew of five minutes is used.
+	TokenValidator *TokenValidator
+	// Enables the authorization of the tokens of the Bot Framework Emulator. They are issued by the EmulatorIssuers
+	// of the Configuration and do not declare a service url. It should only be enabled for local development.
+	AcceptEmulatorTokens bool
+	// The cache which provides the keys of the Bot Framework Emulator. If it is nil the keys are fetched on every
+	// request of the emulator.
+	EmulatorSigningKeyCache *SigningKeyCache
+	// Disables the authorization of incoming requests if the MicrosoftAppId is empty. This matches the Bot
+	// Framework Emulator when it is run without an app id. It has no effect if a MicrosoftAppId is set. The service
+	// urls of unauthenticated activities are not trusted so they have to be added to the TrustedServiceUrls manually.
+	AllowUnauthenticated bool
+	// The function which is called with the reason whenever an incoming request is rejected because it is not
+	// authorized. The error is usually an AuthorizationError.
+	AuthorizationFailedHandleFunction func(req *http.Request, err error)
+	// The dispatcher which handles the activities asynchronously. If it is nil the activities are handled
+	// synchronously by the goroutine of the request.
+	Dispatcher *ActivityDispatcher
 }
 
 // The activityReceivedHandleFunction will gets called on incoming Activity objects for example incoming skype messages.
 // The authorization token which is used to authenticate incoming requests by the microsoft servers.
 // The microsoftAppId which is used to authorize incoming requests
 // Returns a new Endpoint struct object with the default Strict-Transport-Security Header "max-age=63072000; includeSubDomains".
+// The SigningKeys are cached by a new SigningKeyCache.
 func NewEndpointHandler(activityReceivedHandleFunction func(activity *Activity), authorizationToken, microsoftAppId string) (*EndpointHandler) {
 	endpointHandler := &EndpointHandler{
 		AuthorizationToken:             authorizationToken,
 		TlsHeaderValue:                 defaultTlsHeaderValue,
 		ActivityReceivedHandleFunction: activityReceivedHandleFunction,
 		MicrosoftAppId:                 microsoftAppId,
+		SigningKeyCache:                NewSigningKeyCache(),
 	}
 	return endpointHandler
 }
 
-// This method does not cache the SigningKeys
+// The turnReceivedHandleFunction will gets called on incoming Activity objects with a TurnContext which could be
+// used to respond to the activity.
+// The tokenSource which is used to authorize the responses.
+// The microsoftAppId which is used to authorize incoming requests
+// Returns a new Endpoint struct object like NewEndpointHandler.
+func NewTurnEndpointHandler(turnReceivedHandleFunction func(turn *TurnContext), tokenSource TokenSource, microsoftAppId string) (*EndpointHandler) {
+	endpointHandler := NewEndpointHandler(nil, "", microsoftAppId)
+	endpointHandler.TurnReceivedHandleFunction = turnReceivedHandleFunction
+	endpointHandler.TokenSource = tokenSource
+	return endpointHandler
+}
+
+// Appends the middlewares to the Middlewares of the handler. They are called in the order they were added.
+func (endpointHandler *EndpointHandler) Use(middlewares ...Middleware) {
+	endpointHandler.Middlewares = append(endpointHandler.Middlewares, middlewares...)
+}
+
+// The SigningKeys are taken from the SigningKeyCache. If no cache is set they are fetched on every call.
 // The req which should be proved
-func (endpointHandler EndpointHandler) IsAuthorized(req *http.Request) bool {
-	signingKeys, err := GetSigningKeys()
-	if err != nil {
-		return false
-	} else {
-		return endpointHandler.IsAuthorizedWithSigningKeys(req, signingKeys)
-	}
+// Returns nil if the request is authorized or an AuthorizationError which describes the reason.
+func (endpointHandler EndpointHandler) IsAuthorized(req *http.Request) error {
+	_, _, err := endpointHandler.authenticate(req)
+	return err
 }
 
 // The req which should be proved
 // The SigningKeys which can be used to authorize the request
-func (endpointHandler EndpointHandler) IsAuthorizedWithSigningKeys(req *http.Request, signingKeys SigningKeys) bool {
+// Returns nil if the request is authorized or an AuthorizationError which describes the reason.
+func (endpointHandler EndpointHandler) IsAuthorizedWithSigningKeys(req *http.Request, signingKeys SigningKeys) error {
 	var authorizationValue string = req.Header.Get(authorizationHeaderKey)
 	if microsoftJsonWebToken, err := ParseMicrosoftJsonWebToken(authorizationValue);
 		err != nil {
-		return false
+		return wrapAuthorizationError(ErrMalformedHeader, err)
+	} else {
+		_, err := endpointHandler.validate(microsoftJsonWebToken, signingKeys)
+		return err
+	}
+}
+
+// Parses and validates the token of the request. Returns the token and its signing key so the service url and
+// the endorsements could be validated once the activity is decoded.
+func (endpointHandler EndpointHandler) authenticate(req *http.Request) (MicrosoftJsonWebToken, SigningKey, error) {
+	if endpointHandler.authorizationDisabled() {
+		return MicrosoftJsonWebToken{}, SigningKey{}, nil
+	}
+	microsoftJsonWebToken, err := ParseMicrosoftJsonWebToken(req.Header.Get(authorizationHeaderKey))
+	if err != nil {
+		return microsoftJsonWebToken, SigningKey{}, wrapAuthorizationError(ErrMalformedHeader, err)
+	}
+	if endpointHandler.isEmulatorToken(microsoftJsonWebToken) {
+		return endpointHandler.authenticateEmulator(req, microsoftJsonWebToken)
+	}
+	var signingKeys SigningKeys
+	if endpointHandler.SigningKeyCache == nil {
+		signingKeys, err = configurationOrDefault(endpointHandler.Configuration).GetSigningKeysWithContext(req.Context())
+	} else {
+		signingKeys, err = endpointHandler.SigningKeyCache.signingKeysForKeyId(microsoftJsonWebToken.Header.SigningKeyId,
+			endpointHandler.Configuration)
+	}
+	if err != nil {
+		return microsoftJsonWebToken, SigningKey{}, wrapAuthorizationError(ErrKeyFetchFailed, err)
+	}
+	signingKey, err := endpointHandler.validate(microsoftJsonWebToken, signingKeys)
+	return microsoftJsonWebToken, signingKey, err
+}
+
+// Validates the token of the Bot Framework Emulator with the emulator keys and issuers.
+func (endpointHandler EndpointHandler) authenticateEmulator(req *http.Request, microsoftJsonWebToken MicrosoftJsonWebToken) (MicrosoftJsonWebToken, SigningKey, error) {
+	var signingKeys SigningKeys
+	var err error
+	if endpointHandler.EmulatorSigningKeyCache == nil {
+		signingKeys, err = configurationOrDefault(endpointHandler.Configuration).emulatorConfiguration().GetSigningKeysWithContext(req.Context())
 	} else {
-		return microsoftJsonWebToken.Verify(endpointHandler.MicrosoftAppId, signingKeys)
+		signingKeys, err = endpointHandler.EmulatorSigningKeyCache.SigningKeysForKeyId(microsoftJsonWebToken.Header.SigningKeyId)
+	}
+	if err != nil {
+		return microsoftJsonWebToken, SigningKey{}, wrapAuthorizationError(ErrKeyFetchFailed, err)
+	}
+	if err := NewEmulatorTokenValidator(endpointHandler.Configuration).Validate(microsoftJsonWebToken, endpointHandler.MicrosoftAppId, signingKeys, "");
+		err != nil {
+		return microsoftJsonWebToken, SigningKey{}, err
+	} else if err := validateEmulatorAppId(microsoftJsonWebToken, endpointHandler.MicrosoftAppId); err != nil {
+		return microsoftJsonWebToken, SigningKey{}, err
+	}
+	signingKey, _ := signingKeys.key(microsoftJsonWebToken.Header.SigningKeyId)
+	return microsoftJsonWebToken, signingKey, nil
+}
+
+// Validates the endorsements of the signing key and the service url of the token against the decoded activity.
+// Activities without a channel are rejected. The keys of the Bot Framework Emulator are not endorsed and its
+// tokens do not declare a service url so neither is validated for them.
+func (endpointHandler EndpointHandler) validateActivity(microsoftJsonWebToken MicrosoftJsonWebToken, signingKey SigningKey, activity *Activity) error {
+	if endpointHandler.authorizationDisabled() {
+		return nil
+	} else if activity.ChannelID == "" {
+		return newAuthorizationError(ErrMissingEndorsement, missingChannelIdError)
+	} else if endpointHandler.isEmulatorToken(microsoftJsonWebToken) {
+		return nil
+	} else if err := ValidateEndorsement(signingKey, activity.ChannelID); err != nil {
+		return err
 	}
+	return ValidateServiceUrl(microsoftJsonWebToken, activity.ServiceURL)
+}
+
+func (endpointHandler EndpointHandler) authorizationDisabled() bool {
+	return endpointHandler.AllowUnauthenticated && endpointHandler.MicrosoftAppId == ""
+}
+
+func (endpointHandler EndpointHandler) isEmulatorToken(microsoftJsonWebToken MicrosoftJsonWebToken) bool {
+	return endpointHandler.AcceptEmulatorTokens &&
+		configurationOrDefault(endpointHandler.Configuration).IsEmulatorToken(microsoftJsonWebToken)
+}
+
+func (endpointHandler EndpointHandler) validate(microsoftJsonWebToken MicrosoftJsonWebToken, signingKeys SigningKeys) (SigningKey, error) {
+	if err := endpointHandler.tokenValidator().Validate(microsoftJsonWebToken, endpointHandler.MicrosoftAppId, signingKeys, "");
+		err != nil {
+		return SigningKey{}, err
+	}
+	signingKey, _ := signingKeys.key(microsoftJsonWebToken.Header.SigningKeyId)
+	return signingKey, nil
+}
+
+func (endpointHandler EndpointHandler) tokenValidator() *TokenValidator {
+	if endpointHandler.TokenValidator == nil {
+		return NewTokenValidator(endpointHandler.Configuration)
+	}
+	return endpointHandler.TokenValidator
 }
 
 // Internal method to hook skype actions.
@@ -122,14 +263,67 @@ func (endpointHandler EndpointHandler) ServeHTTP(responseWriter http.ResponseWri
 	}
 
 	var activity Activity
-	if !endpointHandler.IsAuthorized(req) {
-		responseWriter.WriteHeader(http.StatusForbidden)
-	} else if err := json.NewDecoder(req.Body).Decode(&activity); err == nil {
+	if microsoftJsonWebToken, signingKey, err := endpointHandler.authenticate(req); err != nil {
+		endpointHandler.rejectUnauthorized(responseWriter, req, err)
+	} else if err := json.NewDecoder(req.Body).Decode(&activity); err != nil {
+		responseWriter.WriteHeader(http.StatusBadRequest)
+	} else if err := endpointHandler.validateActivity(microsoftJsonWebToken, signingKey, &activity); err != nil {
+		endpointHandler.rejectUnauthorized(responseWriter, req, err)
+	} else {
+		endpointHandler.trustServiceUrl(activity.ServiceURL)
+		endpointHandler.dispatch(responseWriter, req, &activity)
+	}
+}
+
+func (endpointHandler EndpointHandler) rejectUnauthorized(responseWriter http.ResponseWriter, req *http.Request, err error) {
+	responseWriter.WriteHeader(http.StatusForbidden)
+	if endpointHandler.AuthorizationFailedHandleFunction != nil {
+		endpointHandler.AuthorizationFailedHandleFunction(req, err)
+	}
+}
+
+// Handles the activity synchronously or queues it at the Dispatcher.
+func (endpointHandler EndpointHandler) dispatch(responseWriter http.ResponseWriter, req *http.Request, activity *Activity) {
+	if endpointHandler.Dispatcher == nil {
+		responseWriter.WriteHeader(http.StatusOK)
+		endpointHandler.handleActivity(req.Context(), activity)
+	} else if endpointHandler.Dispatcher.dispatch(conversationKey(activity), func(ctx context.Context) {
+		endpointHandler.handleActivity(ctx, activity)
+	}) {
 		responseWriter.WriteHeader(http.StatusOK)
-		endpointHandler.ActivityReceivedHandleFunction(&activity)
 	} else {
-		responseWriter.WriteHeader(http.StatusBadRequest)
+		responseWriter.WriteHeader(http.StatusServiceUnavailable)
+	}
+}
+
+// Passes the activity through the middlewares to the handle functions.
+func (endpointHandler EndpointHandler) handleActivity(ctx context.Context, activity *Activity) {
+	chainMiddlewares(endpointHandler.Middlewares, func(activity *Activity) {
+		if endpointHandler.ActivityReceivedHandleFunction != nil {
+			endpointHandler.ActivityReceivedHandleFunction(activity)
+		}
+		if endpointHandler.TurnReceivedHandleFunction != nil {
+			endpointHandler.TurnReceivedHandleFunction(NewTurnContext(ctx, activity, endpointHandler.tokenSource(),
+				endpointHandler.Configuration))
+		}
+	})(activity)
+}
+
+// Trusts the service url of an authorized activity so replies to it could be sent. Nothing is trusted if the
+// authorization is disabled because anyone could have sent the activity.
+func (endpointHandler EndpointHandler) trustServiceUrl(serviceUrl string) {
+	if endpointHandler.authorizationDisabled() {
+		return
+	} else if trustedServiceUrls := configurationOrDefault(endpointHandler.Configuration).TrustedServiceUrls; trustedServiceUrls != nil {
+		trustedServiceUrls.Trust(serviceUrl)
+	}
+}
+
+func (endpointHandler EndpointHandler) tokenSource() TokenSource {
+	if endpointHandler.TokenSource == nil {
+		return StaticTokenSource(endpointHandler.AuthorizationToken)
 	}
+	return endpointHandler.TokenSource
 }
 
 // This method could be used on an Endpoint struct object to setup an own web server which
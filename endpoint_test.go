@@ -0,0 +1,95 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEndpointHandlerWithoutAuthorizationDoesNotTrustServiceUrl(t *testing.T) {
+	var received *Activity
+	endpointHandler := NewEndpointHandler(func(activity *Activity) {
+		received = activity
+	}, "", "")
+	endpointHandler.AllowUnauthenticated = true
+	endpointHandler.Configuration = NewConfiguration()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(
+		`{"type":"message","channelId":"emulator","serviceUrl":"https://evil.example.com/","text":"hello"}`))
+	recorder := httptest.NewRecorder()
+	endpointHandler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("got status %v, expected %v", recorder.Code, http.StatusOK)
+	} else if received == nil || received.Text != "hello" {
+		t.Fatalf("got activity %+v", received)
+	} else if endpointHandler.Configuration.TrustedServiceUrls.IsTrusted("https://evil.example.com/v3/conversations") {
+		t.Error("the service url of an unauthenticated activity is trusted")
+	}
+}
+
+func TestEndpointHandlerValidateActivity(t *testing.T) {
+	emulatorIssuer := NewConfiguration().EmulatorIssuers[0]
+	tests := []struct {
+		name         string
+		issuer       string
+		endorsements []string
+		activity     Activity
+		expected     error
+	}{
+		{name: "endorsed channel", endorsements: []string{"skype"},
+			activity: Activity{ChannelID: "skype", ServiceURL: testServiceUrl}},
+		{name: "without channel", endorsements: []string{"skype"},
+			activity: Activity{ServiceURL: testServiceUrl}, expected: ErrMissingEndorsement},
+		{name: "not endorsed channel", endorsements: []string{"msteams"},
+			activity: Activity{ChannelID: "skype", ServiceURL: testServiceUrl}, expected: ErrMissingEndorsement},
+		{name: "key without endorsements",
+			activity: Activity{ChannelID: "skype", ServiceURL: testServiceUrl}, expected: ErrMissingEndorsement},
+		{name: "other service url", endorsements: []string{"skype"},
+			activity: Activity{ChannelID: "skype", ServiceURL: "https://evil.example.com/"}, expected: ErrServiceUrlMismatch},
+		{name: "emulator", issuer: emulatorIssuer,
+			activity: Activity{ChannelID: "emulator", ServiceURL: "http://localhost:1234"}},
+		{name: "emulator without channel", issuer: emulatorIssuer,
+			activity: Activity{ServiceURL: "http://localhost:1234"}, expected: ErrMissingEndorsement},
+	}
+	for _, test := range tests {
+		endpointHandler := EndpointHandler{MicrosoftAppId: testAppId, AcceptEmulatorTokens: true, Configuration: NewConfiguration()}
+		microsoftJsonWebToken := MicrosoftJsonWebToken{Payload: newTestPayload()}
+		if test.issuer != "" {
+			microsoftJsonWebToken.Payload.Issuer = test.issuer
+		}
+		signingKey := SigningKey{KeyId: testKeyId, Endorsements: test.endorsements}
+
+		err := endpointHandler.validateActivity(microsoftJsonWebToken, signingKey, &test.activity)
+		if test.expected == nil && err != nil {
+			t.Errorf("%v: got error %v, expected none", test.name, err)
+		} else if test.expected != nil && !errors.Is(err, test.expected) {
+			t.Errorf("%v: got error %v, expected %v", test.name, err, test.expected)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	unexpectedHttpStatusCodeTemplate = "The microsoft servers returned an unexpected http status code: %v (%v: %v) for %v"
+	// The maximum number of bytes which are read from the body of a failed response.
+	maximumErrorBodySize = 64 * 1024
+)
+
+// The ErrorResponse is returned by the Bot Connector service if a request failed.
+type ErrorResponse struct {
+	// Describes the error.
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	// Error code, for example BadArgument or ConversationNotFound.
+	Code string `json:"code,omitempty"`
+	// A description of the error.
+	Message string `json:"message,omitempty"`
+}
+
+// The TokenErrorResponse is returned by the token endpoint if an access token could not be issued.
+type TokenErrorResponse struct {
+	// The OAuth error code, for example invalid_client.
+	Error string `json:"error"`
+	// A description of the error.
+	ErrorDescription string `json:"error_description,omitempty"`
+	// The numeric error codes of the microsoft login servers.
+	ErrorCodes []int `json:"error_codes,omitempty"`
+	// The ID of the request which could be used by the microsoft support.
+	TraceId string `json:"trace_id,omitempty"`
+	// The ID which correlates the request across the microsoft services.
+	CorrelationId string `json:"correlation_id,omitempty"`
+}
+
+// The ConnectorError is returned if the Bot Connector service answered a request with an unexpected http status
+// code. It could be inspected with errors.As.
+type ConnectorError struct {
+	// The http status code of the response.
+	StatusCode int
+	// The error code of the ErrorResponse. It is empty if the body could not be decoded.
+	Code string
+	// The error message of the ErrorResponse or the raw body if it could not be decoded.
+	Message string
+	// The url of the failed request.
+	RequestUrl string
+	// The duration which should be waited before the request is sent again. It is zero if the response
+	// did not contain a Retry-After header.
+	RetryAfter time.Duration
+}
+
+func (connectorError *ConnectorError) Error() string {
+	return fmt.Sprintf(unexpectedHttpStatusCodeTemplate, connectorError.StatusCode, connectorError.Code,
+		connectorError.Message, connectorError.RequestUrl)
+}
+
+// The TokenRequestError is returned if the token endpoint answered a token request with an unexpected http
+// status code. It could be inspected with errors.As.
+type TokenRequestError struct {
+	// The http status code of the response.
+	StatusCode int
+	// The OAuth error code of the TokenErrorResponse. It is empty if the body could not be decoded.
+	Code string
+	// The error description of the TokenErrorResponse or the raw body if it could not be decoded.
+	Message string
+	// The url of the failed request.
+	RequestUrl string
+	// The duration which should be waited before the request is sent again. It is zero if the response
+	// did not contain a Retry-After header.
+	RetryAfter time.Duration
+}
+
+func (tokenRequestError *TokenRequestError) Error() string {
+	return fmt.Sprintf(unexpectedHttpStatusCodeTemplate, tokenRequestError.StatusCode, tokenRequestError.Code,
+		tokenRequestError.Message, tokenRequestError.RequestUrl)
+}
+
+// Builds a ConnectorError from the failed response. The body of the response is consumed.
+func newConnectorError(resp *http.Response, requestUrl string) *ConnectorError {
+	connectorError := &ConnectorError{
+		StatusCode: resp.StatusCode,
+		RequestUrl: requestUrl,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	body := readErrorBody(resp)
+	var errorResponse ErrorResponse
+	if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error.Code != "" {
+		connectorError.Code = errorResponse.Error.Code
+		connectorError.Message = errorResponse.Error.Message
+	} else {
+		connectorError.Message = strings.TrimSpace(string(body))
+	}
+	return connectorError
+}
+
+// Builds a TokenRequestError from the failed response. The body of the response is consumed.
+func newTokenRequestError(resp *http.Response, requestUrl string) *TokenRequestError {
+	tokenRequestError := &TokenRequestError{
+		StatusCode: resp.StatusCode,
+		RequestUrl: requestUrl,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	body := readErrorBody(resp)
+	var tokenErrorResponse TokenErrorResponse
+	if err := json.Unmarshal(body, &tokenErrorResponse); err == nil && tokenErrorResponse.Error != "" {
+		tokenRequestError.Code = tokenErrorResponse.Error
+		tokenRequestError.Message = tokenErrorResponse.ErrorDescription
+	} else {
+		tokenRequestError.Message = strings.TrimSpace(string(body))
+	}
+	return tokenRequestError
+}
+
+func readErrorBody(resp *http.Response) []byte {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maximumErrorBodySize))
+	return body
+}
+
+// Parses the value of a Retry-After header which is either a number of seconds or a http date.
+func parseRetryAfter(headerValue string) time.Duration {
+	if headerValue == "" {
+		return 0
+	} else if seconds, err := strconv.Atoi(headerValue); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(headerValue); err == nil {
+		if retryAfter := time.Until(date); retryAfter > 0 {
+			return retryAfter
+		}
+	}
+	return 0
+}
@@ -37,15 +37,16 @@ const (
 	someOtherStuffPath string = "/"
 )
 
-// this function handles our skype activity
-func handleActivity(activity *skypeapi.Activity) {
-	if activity.Type == "message" {
-		// hard coding an auth token is no good practice! I am just doing this to make this example more simple.
-		if err := skypeapi.SendReplyMessage(activity, "Good evening. Nice to meet you!", "YOUR-AUTH-TOKEN");
-			err != nil {
+// the access token manager requests our auth token and refreshes it before it expires
+var accessTokenManager = skypeapi.NewAccessTokenManager("YOUR-APP-ID", "YOUR-APP-PASSWORD")
+
+// this function handles our skype activity. The turn already knows where to send the reply to.
+func handleActivity(turn *skypeapi.TurnContext) {
+	if turn.Activity.IsMessage() {
+		if resourceResponse, err := turn.Reply("Good evening. Nice to meet you!"); err != nil {
 			panic(err)
-		} else {
-			fmt.Println("Successfully sent response message to skype user: " + activity.From.Name)
+		} else if turn.Activity.From != nil {
+			fmt.Println("Successfully sent response message " + resourceResponse.ID + " to skype user: " + turn.Activity.From.Name)
 		}
 	}
 }
@@ -57,12 +58,10 @@ func handleMainPath(writer http.ResponseWriter, req *http.Request) {
 }
 
 func startCustomServerEndpoint() {
-	// bad practice. In real production you should better request the token via skypeapi.RequestAccessToken
-	// WARNING: when using a static authorization token it could expire. In future the will be an automatic refresher
-	authorizationBearerToken := "YOUR-AUTH-TOKEN"
 	mux := http.NewServeMux()
 	// here we setup an own activity handler which listens to the path "/skype/actionhook"
-	mux.Handle(actionHookPath, skypeapi.NewEndpointHandler(handleActivity, authorizationBearerToken, "YOUR-APP-ID"))
+	// the replies in handleActivity are authorized by the accessTokenManager which refreshes its token automatically
+	mux.Handle(actionHookPath, skypeapi.NewTurnEndpointHandler(handleActivity, accessTokenManager, "YOUR-APP-ID"))
 	// here we could probably just handle our main application
 	mux.HandleFunc(someOtherStuffPath, handleMainPath)
 	// here you could provide your own TLS configuration
@@ -0,0 +1,191 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mentionEntityType          = "mention"
+	activityLogTemplate        = "Handled %v activity %v in conversation %v from %v in %v"
+	recoveredPanicLogTemplate  = "Recovered from panic while handling activity %v: %v\n%s"
+	rateLimitExceededTemplate  = "Dropped %v activity %v from %v because the rate limit was exceeded"
+	rateLimitBucketsPruneLimit = 1024
+)
+
+// A Middleware is called for every incoming Activity before the ActivityReceivedHandleFunction. It could inspect
+// or modify the activity and has to call next to pass it on. If next is not called the activity is dropped.
+// Code after the call of next runs after the handle function and all later middlewares returned.
+type Middleware func(activity *Activity, next func(activity *Activity))
+
+// Chains the middlewares in the given order in front of the handleFunction. The first middleware is
+// called first and its next function calls the second one.
+func chainMiddlewares(middlewares []Middleware, handleFunction func(activity *Activity)) func(activity *Activity) {
+	chained := handleFunction
+	for index := len(middlewares) - 1; index >= 0; index-- {
+		middleware, next := middlewares[index], chained
+		chained = func(activity *Activity) {
+			middleware(activity, next)
+		}
+	}
+	return chained
+}
+
+// Returns a Middleware which logs every activity with its type, conversation, sender and the duration of the
+// handling. If the logger is nil the standard logger is used.
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	return func(activity *Activity, next func(activity *Activity)) {
+		start := time.Now()
+		next(activity)
+		logPrintf(logger, activityLogTemplate, activity.Type, activity.ID, activity.conversationId(),
+			activity.fromId(), time.Since(start))
+	}
+}
+
+// Returns a Middleware which recovers from panics in later middlewares and the handle function. The panic is
+// logged with its stack trace. If the logger is nil the standard logger is used.
+func RecoverMiddleware(logger *log.Logger) Middleware {
+	return func(activity *Activity, next func(activity *Activity)) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logPrintf(logger, recoveredPanicLogTemplate, activity.ID, recovered, debug.Stack())
+			}
+		}()
+		next(activity)
+	}
+}
+
+// Returns a Middleware which removes the mentions of the bot from the text of message activities. In group
+// conversations the text starts with a mention of the bot which otherwise has to be removed by every handler.
+func MentionStrippingMiddleware() Middleware {
+	return func(activity *Activity, next func(activity *Activity)) {
+		if activity.IsMessage() {
+			for _, entity := range activity.Entities {
+				if mentionText, ok := botMentionText(entity, activity.recipientId()); ok {
+					activity.Text = strings.Replace(activity.Text, mentionText, "", -1)
+				}
+			}
+			activity.Text = strings.TrimSpace(activity.Text)
+		}
+		next(activity)
+	}
+}
+
+// Returns the text of the entity if it is a mention of the bot with the given ID.
+func botMentionText(entity interface{}, botId string) (string, bool) {
+	mention, ok := entity.(map[string]interface{})
+	if !ok || mention["type"] != mentionEntityType {
+		return "", false
+	}
+	mentioned, ok := mention["mentioned"].(map[string]interface{})
+	if !ok || mentioned["id"] != botId {
+		return "", false
+	}
+	text, ok := mention["text"].(string)
+	return text, ok && text != ""
+}
+
+// Returns a Middleware which limits the number of activities per sender. Every sender may send burst activities
+// at once and one more activity per interval afterwards. Activities which exceed the limit are dropped and
+// logged. If the logger is nil the standard logger is used.
+func RateLimitMiddleware(interval time.Duration, burst int, logger *log.Logger) Middleware {
+	rateLimiter := &rateLimiter{
+		interval: interval,
+		burst:    float64(burst),
+		buckets:  make(map[string]*rateLimitBucket),
+	}
+	return func(activity *Activity, next func(activity *Activity)) {
+		if rateLimiter.allow(activity.fromId(), time.Now()) {
+			next(activity)
+		} else {
+			logPrintf(logger, rateLimitExceededTemplate, activity.Type, activity.ID, activity.fromId())
+		}
+	}
+}
+
+type rateLimiter struct {
+	interval time.Duration
+	burst    float64
+	mutex    sync.Mutex
+	buckets  map[string]*rateLimitBucket
+}
+
+type rateLimitBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// Takes a token from the bucket of the key. Buckets are refilled with one token per interval.
+func (rateLimiter *rateLimiter) allow(key string, now time.Time) bool {
+	rateLimiter.mutex.Lock()
+	defer rateLimiter.mutex.Unlock()
+	if len(rateLimiter.buckets) >= rateLimitBucketsPruneLimit {
+		rateLimiter.prune(now)
+	}
+	bucket, ok := rateLimiter.buckets[key]
+	if !ok {
+		bucket = &rateLimitBucket{tokens: rateLimiter.burst, updatedAt: now}
+		rateLimiter.buckets[key] = bucket
+	}
+	rateLimiter.refill(bucket, now)
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+func (rateLimiter *rateLimiter) refill(bucket *rateLimitBucket, now time.Time) {
+	if rateLimiter.interval > 0 {
+		bucket.tokens += float64(now.Sub(bucket.updatedAt)) / float64(rateLimiter.interval)
+	}
+	if bucket.tokens > rateLimiter.burst {
+		bucket.tokens = rateLimiter.burst
+	}
+	bucket.updatedAt = now
+}
+
+// Removes the buckets which are full again because they do not differ from new ones.
+func (rateLimiter *rateLimiter) prune(now time.Time) {
+	for key, bucket := range rateLimiter.buckets {
+		rateLimiter.refill(bucket, now)
+		if bucket.tokens >= rateLimiter.burst {
+			delete(rateLimiter.buckets, key)
+		}
+	}
+}
+
+func logPrintf(logger *log.Logger, format string, values ...interface{}) {
+	if logger == nil {
+		log.Output(2, fmt.Sprintf(format, values...))
+	} else {
+		logger.Output(2, fmt.Sprintf(format, values...))
+	}
+}
@@ -0,0 +1,146 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff     = 10 * time.Second
+	defaultRetryMultiplier     = 2
+	defaultRetryJitter         = 0.2
+	defaultRetryMaxRetryAfter  = time.Minute
+)
+
+// The RetryPolicy declares how often and when failed requests are sent again. Requests are retried if the
+// server answered with 429 Too Many Requests or 503 Service Unavailable or if the connection could not be
+// established because the request was not processed in these cases. Responses with 502 Bad Gateway or
+// 504 Gateway Timeout and other connection errors are only retried for idempotent requests like token
+// requests, updates and deletes because a posted activity could have been delivered already.
+type RetryPolicy struct {
+	// The maximum number of attempts including the first one.
+	MaxAttempts int
+	// The duration which is waited before the first retry.
+	InitialBackoff time.Duration
+	// The maximum duration which is waited between two attempts.
+	MaxBackoff time.Duration
+	// The factor the backoff is multiplied with after every attempt.
+	Multiplier float64
+	// The fraction of the backoff which is randomly added or subtracted. A value of 0.2 results in a
+	// backoff between 80% and 120% of the computed value.
+	Jitter float64
+	// The maximum duration of a Retry-After header which is honored. If the server asks to wait longer
+	// the request is not retried. If it is zero or negative the default of one minute is used.
+	MaxRetryAfter time.Duration
+}
+
+// Returns a new RetryPolicy with three attempts and a jittered exponential backoff starting at 500 milliseconds.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    defaultRetryMaxAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		Multiplier:     defaultRetryMultiplier,
+		Jitter:         defaultRetryJitter,
+		MaxRetryAfter:  defaultRetryMaxRetryAfter,
+	}
+}
+
+// Returns the duration which is waited before the given retry. The first retry has the number 1.
+func (retryPolicy *RetryPolicy) backoff(retry int) time.Duration {
+	backoff := float64(retryPolicy.InitialBackoff)
+	for i := 1; i < retry; i++ {
+		backoff *= retryPolicy.Multiplier
+	}
+	if retryPolicy.MaxBackoff > 0 && backoff > float64(retryPolicy.MaxBackoff) {
+		backoff = float64(retryPolicy.MaxBackoff)
+	}
+	backoff += backoff * retryPolicy.Jitter * (rand.Float64()*2 - 1)
+	return time.Duration(backoff)
+}
+
+func (retryPolicy *RetryPolicy) maxRetryAfter() time.Duration {
+	if retryPolicy.MaxRetryAfter <= 0 {
+		return defaultRetryMaxRetryAfter
+	}
+	return retryPolicy.MaxRetryAfter
+}
+
+// Sends the request which is built by newRequest. If the retryPolicy is not nil the request is sent again as
+// long as the failure is retryable and the policy allows another attempt. The returned response is the one of
+// the last attempt. Waiting for the next attempt is aborted if the ctx is done.
+func sendWithRetry(ctx context.Context, client *http.Client, retryPolicy *RetryPolicy, idempotent bool, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if retryPolicy == nil || attempt >= retryPolicy.MaxAttempts || ctx.Err() != nil || !isRetryable(resp, err, idempotent) {
+			return resp, err
+		}
+		wait := retryPolicy.backoff(attempt)
+		if resp != nil {
+			if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > retryPolicy.maxRetryAfter() {
+				return resp, err
+			} else if retryAfter > wait {
+				wait = retryAfter
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maximumErrorBodySize))
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func isRetryable(resp *http.Response, err error, idempotent bool) bool {
+	if err != nil {
+		var opError *net.OpError
+		// the request was not sent if the connection could not be established
+		return idempotent || (errors.As(err, &opError) && opError.Op == "dial")
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	default:
+		return false
+	}
+}
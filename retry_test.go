@@ -0,0 +1,278 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Returns a policy without jitter which retries quickly so the tests do not depend on randomness.
+func newTestRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		Multiplier:     2,
+		MaxRetryAfter:  time.Minute,
+	}
+}
+
+// Returns a server which answers every request with the given status code and header and counts the requests.
+func newStatusServer(statusCode int, header http.Header, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(requests, 1)
+		for key, values := range header {
+			responseWriter.Header()[key] = values
+		}
+		responseWriter.WriteHeader(statusCode)
+	}))
+}
+
+func sendTestRequest(ctx context.Context, client *http.Client, retryPolicy *RetryPolicy, idempotent bool, method, requestUrl string) (*http.Response, error) {
+	resp, err := sendWithRetry(ctx, client, retryPolicy, idempotent, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, method, requestUrl, nil)
+	})
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestSendWithRetryStatusCodes(t *testing.T) {
+	tests := []struct {
+		statusCode       int
+		idempotent       bool
+		expectedRequests int32
+	}{
+		{http.StatusTooManyRequests, false, 3},
+		{http.StatusServiceUnavailable, false, 3},
+		{http.StatusBadGateway, false, 1},
+		{http.StatusGatewayTimeout, false, 1},
+		{http.StatusBadGateway, true, 3},
+		{http.StatusGatewayTimeout, true, 3},
+		{http.StatusInternalServerError, true, 1},
+		{http.StatusBadRequest, true, 1},
+		{http.StatusOK, true, 1},
+	}
+	for _, test := range tests {
+		var requests int32
+		server := newStatusServer(test.statusCode, nil, &requests)
+		method := http.MethodPost
+		if test.idempotent {
+			method = http.MethodPut
+		}
+		resp, err := sendTestRequest(context.Background(), server.Client(), newTestRetryPolicy(), test.idempotent, method, server.URL)
+		server.Close()
+		if err != nil {
+			t.Fatalf("%v (idempotent %v): unexpected error: %v", test.statusCode, test.idempotent, err)
+		} else if resp.StatusCode != test.statusCode {
+			t.Errorf("%v (idempotent %v): got status code %v", test.statusCode, test.idempotent, resp.StatusCode)
+		} else if requests != test.expectedRequests {
+			t.Errorf("%v (idempotent %v): got %v requests, expected %v", test.statusCode, test.idempotent, requests, test.expectedRequests)
+		}
+	}
+}
+
+func TestSendWithRetryWithoutPolicy(t *testing.T) {
+	var requests int32
+	server := newStatusServer(http.StatusServiceUnavailable, nil, &requests)
+	defer server.Close()
+	if _, err := sendTestRequest(context.Background(), server.Client(), nil, true, http.MethodGet, server.URL); err != nil {
+		t.Fatal(err)
+	} else if requests != 1 {
+		t.Errorf("got %v requests, expected 1", requests)
+	}
+}
+
+func TestSendWithRetryDialError(t *testing.T) {
+	// the address of a closed listener refuses every connection
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestUrl := "http://" + listener.Addr().String()
+	listener.Close()
+
+	for _, idempotent := range []bool{false, true} {
+		var attempts int
+		_, err := sendWithRetry(context.Background(), http.DefaultClient, newTestRetryPolicy(), idempotent, func() (*http.Request, error) {
+			attempts++
+			return http.NewRequest(http.MethodPost, requestUrl, nil)
+		})
+		if err == nil {
+			t.Fatal("expected a dial error")
+		} else if attempts != 3 {
+			t.Errorf("idempotent %v: got %v attempts, expected 3", idempotent, attempts)
+		}
+	}
+}
+
+func TestSendWithRetryConnectionError(t *testing.T) {
+	// the server accepts the connection and closes it without a response so the request could have been processed
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		connection, _, err := responseWriter.(http.Hijacker).Hijack()
+		if err == nil {
+			connection.Close()
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		idempotent       bool
+		expectedRequests int32
+	}{
+		{false, 1},
+		{true, 3},
+	}
+	for _, test := range tests {
+		atomic.StoreInt32(&requests, 0)
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+		_, err := sendTestRequest(context.Background(), client, newTestRetryPolicy(), test.idempotent, http.MethodPost, server.URL)
+		if err == nil {
+			t.Fatalf("idempotent %v: expected a connection error", test.idempotent)
+		} else if requests := atomic.LoadInt32(&requests); requests != test.expectedRequests {
+			t.Errorf("idempotent %v: got %v requests, expected %v", test.idempotent, requests, test.expectedRequests)
+		}
+	}
+}
+
+func TestSendWithRetryHonorsRetryAfterSeconds(t *testing.T) {
+	retryPolicy := newTestRetryPolicy()
+	retryPolicy.MaxAttempts = 2
+	// the zero MaxRetryAfter of a struct literal falls back to the default
+	for _, retryPolicy := range []*RetryPolicy{retryPolicy, {MaxAttempts: 2}} {
+		var requests int32
+		server := newStatusServer(http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}}, &requests)
+
+		start := time.Now()
+		if _, err := sendTestRequest(context.Background(), server.Client(), retryPolicy, false, http.MethodPost, server.URL); err != nil {
+			t.Fatal(err)
+		}
+		server.Close()
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("%+v: the retry was sent after %v, expected at least one second", retryPolicy, elapsed)
+		} else if requests != 2 {
+			t.Errorf("%+v: got %v requests, expected 2", retryPolicy, requests)
+		}
+	}
+}
+
+func TestSendWithRetryIgnoresLongRetryAfter(t *testing.T) {
+	for _, retryPolicy := range []*RetryPolicy{newTestRetryPolicy(), {MaxAttempts: 3}} {
+		var requests int32
+		server := newStatusServer(http.StatusServiceUnavailable, http.Header{"Retry-After": {"120"}}, &requests)
+
+		start := time.Now()
+		resp, err := sendTestRequest(context.Background(), server.Client(), retryPolicy, true, http.MethodGet, server.URL)
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		} else if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("%+v: got status code %v", retryPolicy, resp.StatusCode)
+		} else if requests != 1 {
+			t.Errorf("%+v: got %v requests, expected 1", retryPolicy, requests)
+		} else if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%+v: the request took %v although it should not be retried", retryPolicy, elapsed)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if retryAfter := parseRetryAfter("7"); retryAfter != 7*time.Second {
+		t.Errorf("seconds: got %v", retryAfter)
+	}
+	date := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	if retryAfter := parseRetryAfter(date); retryAfter < 28*time.Second || retryAfter > 30*time.Second {
+		t.Errorf("http date: got %v", retryAfter)
+	}
+	past := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	for _, headerValue := range []string{"", "-3", "soon", past} {
+		if retryAfter := parseRetryAfter(headerValue); retryAfter != 0 {
+			t.Errorf("%q: got %v, expected 0", headerValue, retryAfter)
+		}
+	}
+}
+
+func TestSendWithRetryCanceledDuringBackoff(t *testing.T) {
+	var requests int32
+	server := newStatusServer(http.StatusServiceUnavailable, nil, &requests)
+	defer server.Close()
+	retryPolicy := newTestRetryPolicy()
+	retryPolicy.InitialBackoff = time.Hour
+	retryPolicy.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := sendTestRequest(ctx, server.Client(), retryPolicy, true, http.MethodGet, server.URL)
+	if err != context.DeadlineExceeded {
+		t.Errorf("got error %v, expected %v", err, context.DeadlineExceeded)
+	} else if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("the backoff was not aborted, it took %v", elapsed)
+	} else if requests != 1 {
+		t.Errorf("got %v requests, expected 1", requests)
+	}
+}
+
+func TestSendJsonRequestRebuildsBodyPerAttempt(t *testing.T) {
+	var mutex sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		mutex.Lock()
+		bodies = append(bodies, string(body))
+		attempt := len(bodies)
+		mutex.Unlock()
+		if attempt == 1 {
+			responseWriter.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			responseWriter.Write([]byte(`{"id":"updated"}`))
+		}
+	}))
+	defer server.Close()
+	configuration := &Configuration{HttpClient: server.Client(), RetryPolicy: newTestRetryPolicy()}
+
+	var resourceResponse ResourceResponse
+	err := sendJsonRequest(context.Background(), configuration, StaticTokenSource("token"), http.MethodPut,
+		server.URL+"/v3/conversations/a/activities/b", &Activity{Type: MessageActivityType, Text: "hello"}, &resourceResponse)
+	if err != nil {
+		t.Fatal(err)
+	} else if resourceResponse.ID != "updated" {
+		t.Errorf("got resource %v", resourceResponse.ID)
+	}
+	expected := `{"type":"message","text":"hello"}`
+	if len(bodies) != 2 || bodies[0] != expected || bodies[1] != expected {
+		t.Errorf("got bodies %q, expected two times %v", bodies, expected)
+	}
+}
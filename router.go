@@ -0,0 +1,217 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import "sync"
+
+// The ActivityRouter dispatches incoming Activity objects to the handle functions which are registered for their
+// type. Its HandleActivity method could be used as the ActivityReceivedHandleFunction of an EndpointHandler:
+//
+//	router := skypeapi.NewActivityRouter()
+//	router.HandleMessage(func(activity *skypeapi.Activity) { ... })
+//	handler := skypeapi.NewEndpointHandler(router.HandleActivity, authorizationToken, microsoftAppId)
+//
+// Besides the type specific handle functions there are hooks for common events like members which were added to
+// a conversation. If neither a handle function nor a hook matches an activity the fallback is called.
+type ActivityRouter struct {
+	mutex                       sync.RWMutex
+	handleFunctions             map[ActivityType]func(activity *Activity)
+	fallbackHandleFunction      func(activity *Activity)
+	membersAddedHooks           []func(activity *Activity, membersAdded []ChannelAccount)
+	membersRemovedHooks         []func(activity *Activity, membersRemoved []ChannelAccount)
+	botAddedHooks               []func(activity *Activity)
+	botRemovedHooks             []func(activity *Activity)
+	botAddedToContactsHooks     []func(activity *Activity)
+	botRemovedFromContactsHooks []func(activity *Activity)
+}
+
+// Returns a new ActivityRouter without any registered handle functions.
+func NewActivityRouter() *ActivityRouter {
+	return &ActivityRouter{
+		handleFunctions: make(map[ActivityType]func(activity *Activity)),
+	}
+}
+
+// Registers the handleFunction for activities of the given type. A previously registered function for the
+// same type is replaced.
+func (activityRouter *ActivityRouter) Handle(activityType ActivityType, handleFunction func(activity *Activity)) {
+	activityRouter.mutex.Lock()
+	defer activityRouter.mutex.Unlock()
+	activityRouter.handleFunctions[activityType] = handleFunction
+}
+
+// Registers the handleFunction for activities of the type "message".
+func (activityRouter *ActivityRouter) HandleMessage(handleFunction func(activity *Activity)) {
+	activityRouter.Handle(MessageActivityType, handleFunction)
+}
+
+// Registers the handleFunction for activities of the type "conversationUpdate".
+func (activityRouter *ActivityRouter) HandleConversationUpdate(handleFunction func(activity *Activity)) {
+	activityRouter.Handle(ConversationUpdateActivityType, handleFunction)
+}
+
+// Registers the handleFunction for activities of the type "contactRelationUpdate".
+func (activityRouter *ActivityRouter) HandleContactRelationUpdate(handleFunction func(activity *Activity)) {
+	activityRouter.Handle(ContactRelationUpdateActivityType, handleFunction)
+}
+
+// Registers the handleFunction for activities of the type "typing".
+func (activityRouter *ActivityRouter) HandleTyping(handleFunction func(activity *Activity)) {
+	activityRouter.Handle(TypingActivityType, handleFunction)
+}
+
+// Registers the handleFunction for activities of the type "endOfConversation".
+func (activityRouter *ActivityRouter) HandleEndOfConversation(handleFunction func(activity *Activity)) {
+	activityRouter.Handle(EndOfConversationActivityType, handleFunction)
+}
+
+// Registers the handleFunction for activities of the type "deleteUserData".
+func (activityRouter *ActivityRouter) HandleDeleteUserData(handleFunction func(activity *Activity)) {
+	activityRouter.Handle(DeleteUserDataActivityType, handleFunction)
+}
+
+// Registers the handleFunction for activities of the type "ping".
+func (activityRouter *ActivityRouter) HandlePing(handleFunction func(activity *Activity)) {
+	activityRouter.Handle(PingActivityType, handleFunction)
+}
+
+// Registers the handleFunction for activities of the type "invoke".
+func (activityRouter *ActivityRouter) HandleInvoke(handleFunction func(activity *Activity)) {
+	activityRouter.Handle(InvokeActivityType, handleFunction)
+}
+
+// Registers the handleFunction which is called for activities which are not handled by any other handle
+// function or hook.
+func (activityRouter *ActivityRouter) HandleFallback(handleFunction func(activity *Activity)) {
+	activityRouter.mutex.Lock()
+	defer activityRouter.mutex.Unlock()
+	activityRouter.fallbackHandleFunction = handleFunction
+}
+
+// Registers a hook which is called for "conversationUpdate" activities with members who joined the conversation.
+func (activityRouter *ActivityRouter) OnMembersAdded(hook func(activity *Activity, membersAdded []ChannelAccount)) {
+	activityRouter.mutex.Lock()
+	defer activityRouter.mutex.Unlock()
+	activityRouter.membersAddedHooks = append(activityRouter.membersAddedHooks, hook)
+}
+
+// Registers a hook which is called for "conversationUpdate" activities with members who left the conversation.
+func (activityRouter *ActivityRouter) OnMembersRemoved(hook func(activity *Activity, membersRemoved []ChannelAccount)) {
+	activityRouter.mutex.Lock()
+	defer activityRouter.mutex.Unlock()
+	activityRouter.membersRemovedHooks = append(activityRouter.membersRemovedHooks, hook)
+}
+
+// Registers a hook which is called if the bot itself was added to a conversation.
+func (activityRouter *ActivityRouter) OnBotAdded(hook func(activity *Activity)) {
+	activityRouter.mutex.Lock()
+	defer activityRouter.mutex.Unlock()
+	activityRouter.botAddedHooks = append(activityRouter.botAddedHooks, hook)
+}
+
+// Registers a hook which is called if the bot itself was removed from a conversation.
+func (activityRouter *ActivityRouter) OnBotRemoved(hook func(activity *Activity)) {
+	activityRouter.mutex.Lock()
+	defer activityRouter.mutex.Unlock()
+	activityRouter.botRemovedHooks = append(activityRouter.botRemovedHooks, hook)
+}
+
+// Registers a hook which is called if a user added the bot to their contacts list.
+func (activityRouter *ActivityRouter) OnBotAddedToContacts(hook func(activity *Activity)) {
+	activityRouter.mutex.Lock()
+	defer activityRouter.mutex.Unlock()
+	activityRouter.botAddedToContactsHooks = append(activityRouter.botAddedToContactsHooks, hook)
+}
+
+// Registers a hook which is called if a user removed the bot from their contacts list.
+func (activityRouter *ActivityRouter) OnBotRemovedFromContacts(hook func(activity *Activity)) {
+	activityRouter.mutex.Lock()
+	defer activityRouter.mutex.Unlock()
+	activityRouter.botRemovedFromContactsHooks = append(activityRouter.botRemovedFromContactsHooks, hook)
+}
+
+// Dispatches the activity to the handle function of its type and to all matching hooks. If none of them
+// matches the fallback is called.
+func (activityRouter *ActivityRouter) HandleActivity(activity *Activity) {
+	activityRouter.mutex.RLock()
+	handleFunction := activityRouter.handleFunctions[activity.Type]
+	fallbackHandleFunction := activityRouter.fallbackHandleFunction
+	var hooks []func()
+	switch activity.Type {
+	case ConversationUpdateActivityType:
+		if len(activity.MembersAdded) != 0 {
+			for _, hook := range activityRouter.membersAddedHooks {
+				hook := hook
+				hooks = append(hooks, func() { hook(activity, activity.MembersAdded) })
+			}
+			if activity.MembersAddedIncludesBot() {
+				hooks = appendActivityHooks(hooks, activityRouter.botAddedHooks, activity)
+			}
+		}
+		if len(activity.MembersRemoved) != 0 {
+			for _, hook := range activityRouter.membersRemovedHooks {
+				hook := hook
+				hooks = append(hooks, func() { hook(activity, activity.MembersRemoved) })
+			}
+			if activity.MembersRemovedIncludesBot() {
+				hooks = appendActivityHooks(hooks, activityRouter.botRemovedHooks, activity)
+			}
+		}
+	case ContactRelationUpdateActivityType:
+		if activity.IsBotAddedToContacts() {
+			hooks = appendActivityHooks(hooks, activityRouter.botAddedToContactsHooks, activity)
+		} else if activity.IsBotRemovedFromContacts() {
+			hooks = appendActivityHooks(hooks, activityRouter.botRemovedFromContactsHooks, activity)
+		}
+	}
+	activityRouter.mutex.RUnlock()
+
+	if handleFunction != nil {
+		handleFunction(activity)
+	}
+	for _, hook := range hooks {
+		hook()
+	}
+	if handleFunction == nil && len(hooks) == 0 && fallbackHandleFunction != nil {
+		fallbackHandleFunction(activity)
+	}
+}
+
+func appendActivityHooks(hooks []func(), activityHooks []func(activity *Activity), activity *Activity) []func() {
+	for _, activityHook := range activityHooks {
+		activityHook := activityHook
+		hooks = append(hooks, func() { activityHook(activity) })
+	}
+	return hooks
+}
+
+// Returns true if the accounts contain an account with the ID of the given account.
+func containsAccount(accounts []ChannelAccount, account ChannelAccount) bool {
+	for _, candidate := range accounts {
+		if candidate.ID == account.ID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	untrustedServiceUrlTemplate = "%w: %v"
+)
+
+// Returned if an outgoing request should be sent to a service url which is not trusted.
+var ErrUntrustedServiceUrl = errors.New("The service url is not trusted")
+
+// The ServiceUrlRegistry contains the service urls the credentials of the bot are sent to. The EndpointHandler
+// trusts the service url of every authorized activity. Service urls which are used for proactive messages
+// have to be trusted explicitly.
+type ServiceUrlRegistry struct {
+	mutex       sync.RWMutex
+	serviceUrls map[string]bool
+}
+
+// Returns a new ServiceUrlRegistry without any trusted service urls.
+func NewServiceUrlRegistry() *ServiceUrlRegistry {
+	return &ServiceUrlRegistry{
+		serviceUrls: make(map[string]bool),
+	}
+}
+
+// Trusts the service url and all urls below it.
+func (serviceUrlRegistry *ServiceUrlRegistry) Trust(serviceUrl string) {
+	if normalizedServiceUrl, ok := normalizeServiceUrl(serviceUrl); ok {
+		serviceUrlRegistry.mutex.Lock()
+		defer serviceUrlRegistry.mutex.Unlock()
+		serviceUrlRegistry.serviceUrls[normalizedServiceUrl] = true
+	}
+}
+
+// Removes the service url from the trusted ones.
+func (serviceUrlRegistry *ServiceUrlRegistry) Revoke(serviceUrl string) {
+	if normalizedServiceUrl, ok := normalizeServiceUrl(serviceUrl); ok {
+		serviceUrlRegistry.mutex.Lock()
+		defer serviceUrlRegistry.mutex.Unlock()
+		delete(serviceUrlRegistry.serviceUrls, normalizedServiceUrl)
+	}
+}
+
+// Returns true if the requestUrl is a trusted service url or below one.
+func (serviceUrlRegistry *ServiceUrlRegistry) IsTrusted(requestUrl string) bool {
+	normalizedRequestUrl, ok := normalizeServiceUrl(requestUrl)
+	if !ok {
+		return false
+	}
+	// the scheme and host are never stripped
+	minimumLength := strings.Index(normalizedRequestUrl, "://") + len("://")
+	serviceUrlRegistry.mutex.RLock()
+	defer serviceUrlRegistry.mutex.RUnlock()
+	for {
+		if serviceUrlRegistry.serviceUrls[normalizedRequestUrl] {
+			return true
+		}
+		index := strings.LastIndex(normalizedRequestUrl, "/")
+		if index < minimumLength {
+			return false
+		}
+		normalizedRequestUrl = normalizedRequestUrl[:index]
+	}
+}
+
+// Returns an error which wraps ErrUntrustedServiceUrl if the registry is not nil and the requestUrl is not trusted.
+func (serviceUrlRegistry *ServiceUrlRegistry) check(requestUrl string) error {
+	if serviceUrlRegistry == nil || serviceUrlRegistry.IsTrusted(requestUrl) {
+		return nil
+	}
+	return fmt.Errorf(untrustedServiceUrlTemplate, ErrUntrustedServiceUrl, requestUrl)
+}
+
+// Returns the url without query, fragment and trailing slashes and with a lower case scheme and host.
+func normalizeServiceUrl(rawUrl string) (string, bool) {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil || parsedUrl.Scheme == "" || parsedUrl.Host == "" {
+		return "", false
+	}
+	return strings.ToLower(parsedUrl.Scheme) + "://" + strings.ToLower(parsedUrl.Host) +
+		strings.TrimRight(parsedUrl.EscapedPath(), "/"), true
+}
@@ -0,0 +1,132 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	// registers the hash functions which are used by the supported algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+const (
+	rs384Algorithm = "RS384"
+	rs512Algorithm = "RS512"
+
+	missingKeyMaterialError   = "The signing key %v neither contains a certificate nor a modulus and exponent"
+	malformedCertificateError = "The certificate of the signing key %v could not be decoded"
+	unsupportedPublicKeyError = "The certificate of the signing key %v does not contain a RSA public key"
+	malformedModulusError     = "The modulus of the signing key %v is not valid"
+	malformedExponentError    = "The exponent of the signing key %v is not valid"
+	unsupportedKeyTypeError   = "The signing key %v has the unsupported key type: %v"
+)
+
+// The hash functions of the supported RSA signature algorithms.
+var signatureHashes = map[string]crypto.Hash{
+	rs256Algorithm: crypto.SHA256,
+	rs384Algorithm: crypto.SHA384,
+	rs512Algorithm: crypto.SHA512,
+}
+
+// Returns the RSA public key of the signing key. The key is taken from the first certificate of the X5C chain. If the
+// chain is empty it is built from the modulus N and the exponent E.
+func (signingKey SigningKey) PublicKey() (*rsa.PublicKey, error) {
+	if signingKey.Kty != "" && signingKey.Kty != "RSA" {
+		return nil, fmt.Errorf(unsupportedKeyTypeError, signingKey.KeyId, signingKey.Kty)
+	} else if len(signingKey.X5C) > 0 && signingKey.X5C[0] != "" {
+		return signingKey.certificatePublicKey()
+	} else if signingKey.N != "" && signingKey.E != "" {
+		return signingKey.modulusPublicKey()
+	}
+	return nil, fmt.Errorf(missingKeyMaterialError, signingKey.KeyId)
+}
+
+func (signingKey SigningKey) certificatePublicKey() (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(parseCertificateString(signingKey.X5C[0])))
+	if block == nil {
+		return nil, fmt.Errorf(malformedCertificateError, signingKey.KeyId)
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if publicKey, ok := certificate.PublicKey.(*rsa.PublicKey); ok {
+		return publicKey, nil
+	}
+	return nil, fmt.Errorf(unsupportedPublicKeyError, signingKey.KeyId)
+}
+
+func (signingKey SigningKey) modulusPublicKey() (*rsa.PublicKey, error) {
+	modulus, err := decodeBase64UrlUint(signingKey.N)
+	if err != nil || modulus.Sign() <= 0 {
+		return nil, fmt.Errorf(malformedModulusError, signingKey.KeyId)
+	}
+	exponent, err := decodeBase64UrlUint(signingKey.E)
+	// the exponent has to fit into an int and be odd to be a valid RSA exponent
+	if err != nil || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 || exponent.Bit(0) == 0 {
+		return nil, fmt.Errorf(malformedExponentError, signingKey.KeyId)
+	}
+	return &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}, nil
+}
+
+// Decodes the unsigned big-endian integer which is encoded as base64url as described in RFC 7518. Some issuers
+// add padding to the values so it is removed before decoding.
+func decodeBase64UrlUint(value string) (*big.Int, error) {
+	for len(value) > 0 && value[len(value)-1] == '=' {
+		value = value[:len(value)-1]
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	} else if len(decoded) == 0 {
+		return nil, errors.New("The value is empty")
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
+
+// Verifies the signature of the token with the public key of the signing key and the algorithm of the token header.
+// Returns an AuthorizationError if the key is malformed or the signature is not valid.
+func (microSoftJsonWebToken MicrosoftJsonWebToken) verifySignature(signingKey SigningKey) error {
+	hash, ok := signatureHashes[microSoftJsonWebToken.Header.Algorithm]
+	if !ok || !hash.Available() {
+		return newAuthorizationError(ErrUnsupportedAlgorithm, unsupportedAlgorithmError, microSoftJsonWebToken.Header.Algorithm)
+	}
+	publicKey, err := signingKey.PublicKey()
+	if err != nil {
+		return wrapAuthorizationError(ErrMalformedKey, err)
+	}
+	hasher := hash.New()
+	hasher.Write([]byte(microSoftJsonWebToken.HeaderBase64 + splitCharacter + microSoftJsonWebToken.PayloadBase64))
+	if err := rsa.VerifyPKCS1v15(publicKey, hash, hasher.Sum(nil), microSoftJsonWebToken.VerifySignature); err != nil {
+		return newAuthorizationError(ErrBadSignature, badSignatureError)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// Returns the DER encoded self-signed certificate of the public key as it is contained in the X5C chain.
+func newTestCertificate(t *testing.T, publicKey crypto.PublicKey, privateKey crypto.Signer) string {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: testIssuer},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(certificate)
+}
+
+func TestVerifySignature(t *testing.T) {
+	privateKey := newTestPrivateKey(t)
+	otherPrivateKey := newTestPrivateKey(t)
+	ecdsaPrivateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modulusKey := newTestSigningKey(privateKey)
+	withModulus := func(n, e string) SigningKey {
+		return SigningKey{Kty: "RSA", KeyId: testKeyId, N: n, E: e}
+	}
+	tests := []struct {
+		name       string
+		signingKey SigningKey
+		expected   error
+	}{
+		{"modulus and exponent", modulusKey, nil},
+		{"modulus and exponent without key type", withModulus(modulusKey.N, modulusKey.E), nil},
+		{"padded modulus and exponent", withModulus(modulusKey.N+"==", modulusKey.E+"="), nil},
+		{"certificate", SigningKey{Kty: "RSA", KeyId: testKeyId,
+			X5C: []string{newTestCertificate(t, &privateKey.PublicKey, privateKey)}}, nil},
+		{"certificate before modulus", SigningKey{Kty: "RSA", KeyId: testKeyId, N: modulusKey.N, E: modulusKey.E,
+			X5C: []string{newTestCertificate(t, &otherPrivateKey.PublicKey, otherPrivateKey)}}, ErrBadSignature},
+		{"empty certificate falls back to modulus", SigningKey{Kty: "RSA", KeyId: testKeyId, N: modulusKey.N,
+			E: modulusKey.E, X5C: []string{""}}, nil},
+		{"other modulus", newTestSigningKey(otherPrivateKey), ErrBadSignature},
+		{"other certificate", SigningKey{KeyId: testKeyId,
+			X5C: []string{newTestCertificate(t, &otherPrivateKey.PublicKey, otherPrivateKey)}}, ErrBadSignature},
+		{"no key material", SigningKey{Kty: "RSA", KeyId: testKeyId}, ErrMalformedKey},
+		{"empty certificate chain", SigningKey{Kty: "RSA", KeyId: testKeyId, X5C: []string{}}, ErrMalformedKey},
+		{"empty certificate", SigningKey{Kty: "RSA", KeyId: testKeyId, X5C: []string{""}}, ErrMalformedKey},
+		{"certificate which is no base64", SigningKey{KeyId: testKeyId, X5C: []string{"not a certificate!"}}, ErrMalformedKey},
+		{"certificate which is no DER", SigningKey{KeyId: testKeyId, X5C: []string{"aGVsbG8gd29ybGQ="}}, ErrMalformedKey},
+		{"certificate of ECDSA key", SigningKey{KeyId: testKeyId,
+			X5C: []string{newTestCertificate(t, &ecdsaPrivateKey.PublicKey, ecdsaPrivateKey)}}, ErrMalformedKey},
+		{"non RSA key type", SigningKey{Kty: "EC", KeyId: testKeyId, N: modulusKey.N, E: modulusKey.E}, ErrMalformedKey},
+		{"symmetric key type", SigningKey{Kty: "oct", KeyId: testKeyId, N: modulusKey.N, E: modulusKey.E}, ErrMalformedKey},
+		{"modulus without exponent", withModulus(modulusKey.N, ""), ErrMalformedKey},
+		{"modulus which is no base64", withModulus("not a modulus!", modulusKey.E), ErrMalformedKey},
+		{"zero modulus", withModulus("AA", modulusKey.E), ErrMalformedKey},
+		{"short modulus", withModulus("Aw", modulusKey.E), ErrBadSignature},
+		{"exponent which is no base64", withModulus(modulusKey.N, "not an exponent!"), ErrMalformedKey},
+		{"zero exponent", withModulus(modulusKey.N, "AA"), ErrMalformedKey},
+		{"even exponent", withModulus(modulusKey.N, "BA"), ErrMalformedKey},
+		{"exponent one", withModulus(modulusKey.N, "AQ"), ErrMalformedKey},
+		{"exponent larger than an int", withModulus(modulusKey.N, "AQAAAAAAAAAB"), ErrMalformedKey},
+	}
+	header := JwtHeader{Type: "JWT", Algorithm: rs256Algorithm, SigningKeyId: testKeyId}
+	microsoftJsonWebToken := signTestToken(t, privateKey, header, newTestPayload())
+	for _, test := range tests {
+		err := microsoftJsonWebToken.verifySignature(test.signingKey)
+		if test.expected == nil && err != nil {
+			t.Errorf("%v: got error %v, expected none", test.name, err)
+		} else if test.expected != nil && !errors.Is(err, test.expected) {
+			t.Errorf("%v: got error %v, expected %v", test.name, err, test.expected)
+		}
+		if _, err := test.signingKey.PublicKey(); (err != nil) != (test.expected == ErrMalformedKey) {
+			t.Errorf("%v: got public key error %v", test.name, err)
+		}
+	}
+}
+
+func TestVerifySignatureOfModifiedToken(t *testing.T) {
+	privateKey := newTestPrivateKey(t)
+	signingKey := newTestSigningKey(privateKey)
+	header := JwtHeader{Type: "JWT", Algorithm: rs256Algorithm, SigningKeyId: testKeyId}
+	tests := []struct {
+		name   string
+		modify func(microsoftJsonWebToken *MicrosoftJsonWebToken)
+	}{
+		{"payload", func(microsoftJsonWebToken *MicrosoftJsonWebToken) {
+			microsoftJsonWebToken.PayloadBase64 = base64.RawURLEncoding.EncodeToString([]byte(`{"aud":"other-app-id"}`))
+		}},
+		{"truncated signature", func(microsoftJsonWebToken *MicrosoftJsonWebToken) {
+			microsoftJsonWebToken.VerifySignature = microsoftJsonWebToken.VerifySignature[:10]
+		}},
+		{"empty signature", func(microsoftJsonWebToken *MicrosoftJsonWebToken) {
+			microsoftJsonWebToken.VerifySignature = nil
+		}},
+		{"algorithm", func(microsoftJsonWebToken *MicrosoftJsonWebToken) {
+			microsoftJsonWebToken.Header.Algorithm = rs512Algorithm
+		}},
+	}
+	for _, test := range tests {
+		microsoftJsonWebToken := signTestToken(t, privateKey, header, newTestPayload())
+		test.modify(&microsoftJsonWebToken)
+		if err := microsoftJsonWebToken.verifySignature(signingKey); !errors.Is(err, ErrBadSignature) {
+			t.Errorf("%v: got error %v, expected %v", test.name, err, ErrBadSignature)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// The default interval in which the SigningKeys are fetched again.
+	defaultSigningKeysRefreshInterval = 24 * time.Hour
+	// The default minimum duration between two fetches which are triggered by unknown key ids.
+	defaultSigningKeysMinimumRefetchInterval = 5 * time.Minute
+	// The minimum duration between two fetches as long as no keys could be fetched. It is shorter than the
+	// MinimumRefetchInterval so a single failed fetch at startup does not reject every request for minutes.
+	emptySigningKeysRefetchInterval = 5 * time.Second
+
+	emptySigningKeysError = "The fetched signing keys are empty"
+)
+
+// The SigningKeyCache caches the SigningKeys which are used to authorize incoming requests. The keys are fetched
+// again after the RefreshInterval or if a token references an unknown key id. Refetches which are caused by
+// unknown key ids are rate limited by the MinimumRefetchInterval so requests with made up key ids can not
+// trigger a fetch on every request. If a fetch fails or returns no keys the previously fetched keys are still served.
+type SigningKeyCache struct {
+	// The interval in which the SigningKeys are fetched again.
+	RefreshInterval time.Duration
+	// The minimum duration between two fetches which are triggered by unknown key ids or failed fetches.
+	MinimumRefetchInterval time.Duration
+	// The Configuration which is used to fetch the keys. If it is nil the Configuration of the EndpointHandler
+	// which uses the cache is used and if that is nil as well the DefaultConfiguration.
+	Configuration *Configuration
+
+	mutex          sync.RWMutex
+	signingKeys    SigningKeys
+	fetchedAt      time.Time
+	lastFetchError error
+
+	fetchMutex     sync.Mutex
+	lastFetchStart time.Time
+
+	lifecycleMutex sync.Mutex
+	stop           context.CancelFunc
+	stopped        chan struct{}
+}
+
+// Returns a new SigningKeyCache which refreshes its keys once a day and allows a refetch caused by an unknown
+// key id every five minutes. The keys are fetched lazily on the first request. Call Start to refresh them
+// in the background.
+func NewSigningKeyCache() *SigningKeyCache {
+	return &SigningKeyCache{
+		RefreshInterval:        defaultSigningKeysRefreshInterval,
+		MinimumRefetchInterval: defaultSigningKeysMinimumRefetchInterval,
+	}
+}
+
+// Returns the cached SigningKeys. The keys are fetched if the cache is empty or the keys are older than the
+// RefreshInterval. If this fetch fails but there are cached keys the stale keys are returned.
+func (signingKeyCache *SigningKeyCache) SigningKeys() (SigningKeys, error) {
+	return signingKeyCache.signingKeysWithConfiguration(nil)
+}
+
+func (signingKeyCache *SigningKeyCache) signingKeysWithConfiguration(configuration *Configuration) (SigningKeys, error) {
+	signingKeyCache.mutex.RLock()
+	signingKeys, fetchedAt := signingKeyCache.signingKeys, signingKeyCache.fetchedAt
+	signingKeyCache.mutex.RUnlock()
+	if !fetchedAt.IsZero() && time.Since(fetchedAt) < signingKeyCache.RefreshInterval {
+		return signingKeys, nil
+	}
+	return signingKeyCache.fetch(fetchedAt, configuration)
+}
+
+// Returns the cached SigningKeys and makes sure they contain the given key id if possible. If the key id is
+// unknown the keys are fetched again unless the last fetch happened within the MinimumRefetchInterval.
+func (signingKeyCache *SigningKeyCache) SigningKeysForKeyId(keyId string) (SigningKeys, error) {
+	return signingKeyCache.signingKeysForKeyId(keyId, nil)
+}
+
+// Returns the SigningKeys like SigningKeysForKeyId. If the cache has no Configuration the given one is used.
+func (signingKeyCache *SigningKeyCache) signingKeysForKeyId(keyId string, configuration *Configuration) (SigningKeys, error) {
+	signingKeys, err := signingKeyCache.signingKeysWithConfiguration(configuration)
+	if err != nil || signingKeys.hasKey(keyId) {
+		return signingKeys, err
+	}
+	signingKeyCache.mutex.RLock()
+	fetchedAt := signingKeyCache.fetchedAt
+	signingKeyCache.mutex.RUnlock()
+	return signingKeyCache.fetch(fetchedAt, configuration)
+}
+
+// Fetches the SigningKeys immediately regardless of the age of the cached keys.
+func (signingKeyCache *SigningKeyCache) Refresh() error {
+	return signingKeyCache.RefreshWithContext(context.Background())
+}
+
+// Fetches the SigningKeys like Refresh. The requests are canceled if the ctx is done.
+func (signingKeyCache *SigningKeyCache) RefreshWithContext(ctx context.Context) error {
+	return signingKeyCache.refresh(ctx, nil)
+}
+
+func (signingKeyCache *SigningKeyCache) refresh(ctx context.Context, configuration *Configuration) error {
+	signingKeyCache.fetchMutex.Lock()
+	defer signingKeyCache.fetchMutex.Unlock()
+	return signingKeyCache.fetchLocked(ctx, configuration)
+}
+
+// Starts a goroutine which fetches the SigningKeys now and then refreshes them in the background after every
+// RefreshInterval. Failed fetches are retried after the MinimumRefetchInterval or, as long as no keys could be
+// fetched, after five seconds. Calling Start on a running cache has no effect.
+func (signingKeyCache *SigningKeyCache) Start() {
+	signingKeyCache.start(nil)
+}
+
+func (signingKeyCache *SigningKeyCache) start(configuration *Configuration) {
+	signingKeyCache.lifecycleMutex.Lock()
+	defer signingKeyCache.lifecycleMutex.Unlock()
+	if signingKeyCache.stop != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	signingKeyCache.stop = cancel
+	signingKeyCache.stopped = make(chan struct{})
+	go signingKeyCache.refreshLoop(ctx, signingKeyCache.stopped, configuration)
+}
+
+// Stops the background refresh which was started by Start and waits until the goroutine exited. A running
+// fetch is canceled. The cached keys are still served afterwards.
+func (signingKeyCache *SigningKeyCache) Stop() {
+	signingKeyCache.lifecycleMutex.Lock()
+	defer signingKeyCache.lifecycleMutex.Unlock()
+	if signingKeyCache.stop == nil {
+		return
+	}
+	signingKeyCache.stop()
+	<-signingKeyCache.stopped
+	signingKeyCache.stop = nil
+	signingKeyCache.stopped = nil
+}
+
+func (signingKeyCache *SigningKeyCache) refreshLoop(ctx context.Context, stopped chan<- struct{}, configuration *Configuration) {
+	defer close(stopped)
+	for {
+		wait := signingKeyCache.RefreshInterval
+		if err := signingKeyCache.refresh(ctx, configuration); err != nil {
+			wait = signingKeyCache.refetchInterval()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Fetches the keys unless another caller already fetched them after seenFetchedAt or the last fetch attempt
+// happened within the MinimumRefetchInterval. In both cases the cached keys are returned.
+func (signingKeyCache *SigningKeyCache) fetch(seenFetchedAt time.Time, configuration *Configuration) (SigningKeys, error) {
+	signingKeyCache.fetchMutex.Lock()
+	defer signingKeyCache.fetchMutex.Unlock()
+	signingKeyCache.mutex.RLock()
+	fetchedAt := signingKeyCache.fetchedAt
+	signingKeyCache.mutex.RUnlock()
+	if fetchedAt.After(seenFetchedAt) ||
+		(!signingKeyCache.lastFetchStart.IsZero() && time.Since(signingKeyCache.lastFetchStart) < signingKeyCache.refetchInterval()) {
+		return signingKeyCache.cached()
+	}
+	// the fetch is shared by all waiting callers so it is not bound to the ctx of a single request
+	signingKeyCache.fetchLocked(context.Background(), configuration)
+	return signingKeyCache.cached()
+}
+
+// Has to be called while holding the fetchMutex.
+func (signingKeyCache *SigningKeyCache) fetchLocked(ctx context.Context, configuration *Configuration) error {
+	fetchStart := time.Now()
+	signingKeys, err := signingKeyCache.configuration(configuration).GetSigningKeysWithContext(ctx)
+	if ctx.Err() != nil {
+		// a canceled fetch does not say anything about the availability of the keys so the next caller fetches again
+		return err
+	}
+	signingKeyCache.lastFetchStart = fetchStart
+	if err == nil && len(signingKeys.Keys) == 0 {
+		// an empty key set would reject every request so the previous keys are kept
+		err = errors.New(emptySigningKeysError)
+	}
+	signingKeyCache.mutex.Lock()
+	defer signingKeyCache.mutex.Unlock()
+	signingKeyCache.lastFetchError = err
+	if err == nil {
+		signingKeyCache.signingKeys = signingKeys
+		signingKeyCache.fetchedAt = time.Now()
+	}
+	return err
+}
+
+// Returns the Configuration of the cache. If it has none the fallback is used and if that is nil as well the
+// DefaultConfiguration.
+func (signingKeyCache *SigningKeyCache) configuration(fallback *Configuration) *Configuration {
+	if signingKeyCache.Configuration != nil {
+		return signingKeyCache.Configuration
+	}
+	return configurationOrDefault(fallback)
+}
+
+// Returns the minimum duration between two fetches. It is shortened while no keys have been fetched so far.
+func (signingKeyCache *SigningKeyCache) refetchInterval() time.Duration {
+	signingKeyCache.mutex.RLock()
+	fetchedAt := signingKeyCache.fetchedAt
+	signingKeyCache.mutex.RUnlock()
+	if fetchedAt.IsZero() && signingKeyCache.MinimumRefetchInterval > emptySigningKeysRefetchInterval {
+		return emptySigningKeysRefetchInterval
+	}
+	return signingKeyCache.MinimumRefetchInterval
+}
+
+// Returns the cached keys. The error of the last fetch is only returned if no keys were fetched so far.
+func (signingKeyCache *SigningKeyCache) cached() (SigningKeys, error) {
+	signingKeyCache.mutex.RLock()
+	defer signingKeyCache.mutex.RUnlock()
+	if signingKeyCache.fetchedAt.IsZero() {
+		return SigningKeys{}, signingKeyCache.lastFetchError
+	}
+	return signingKeyCache.signingKeys, nil
+}
+
+func (signingKeys SigningKeys) hasKey(keyId string) bool {
+	_, ok := signingKeys.key(keyId)
+	return ok
+}
+
+func (signingKeys SigningKeys) key(keyId string) (SigningKey, bool) {
+	for _, key := range signingKeys.Keys {
+		if key.KeyId == keyId {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
+}
@@ -0,0 +1,68 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Returns a server which serves the OpenID metadata and answers the requests of the keys with the statusCode.
+func newSigningKeysServer(statusCode int) *httptest.Server {
+	server := httptest.NewServer(nil)
+	server.Config.Handler = http.HandlerFunc(func(responseWriter http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/openid" {
+			responseWriter.Write([]byte(`{"jwks_uri":"` + server.URL + `/keys"}`))
+			return
+		}
+		responseWriter.WriteHeader(statusCode)
+		if statusCode == http.StatusOK {
+			responseWriter.Write([]byte(`{"keys":[{"kty":"RSA","kid":"` + testKeyId + `","n":"AQAB","e":"AQAB"}]}`))
+		}
+	})
+	return server
+}
+
+func TestSigningKeyCacheFetchesAfterCanceledFetch(t *testing.T) {
+	for _, statusCode := range []int{http.StatusOK, http.StatusInternalServerError} {
+		server := newSigningKeysServer(statusCode)
+		signingKeyCache := NewSigningKeyCache()
+		signingKeyCache.Configuration = &Configuration{OpenIdMetadataUrl: server.URL + "/openid", HttpClient: server.Client()}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := signingKeyCache.RefreshWithContext(ctx); err == nil {
+			t.Errorf("%v: the canceled fetch succeeded", statusCode)
+		}
+
+		signingKeys, err := signingKeyCache.SigningKeysForKeyId(testKeyId)
+		server.Close()
+		if statusCode == http.StatusOK && (err != nil || !signingKeys.hasKey(testKeyId)) {
+			t.Errorf("%v: got keys %+v and error %v", statusCode, signingKeys, err)
+		} else if statusCode != http.StatusOK && err == nil {
+			t.Errorf("%v: got keys %+v without an error", statusCode, signingKeys)
+		}
+	}
+}
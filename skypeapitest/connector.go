@@ -0,0 +1,285 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+// Package skypeapitest provides utilities to test bots which are built with the skypeapi package without sending
+// requests to the microsoft servers.
+package skypeapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/michivip/skypeapi"
+)
+
+const (
+	openIdMetadataPath = "/.well-known/openidconfiguration"
+	signingKeysPath    = "/keys"
+	tokenPath          = "/token"
+	conversationsPath  = "/v3/conversations"
+
+	// The access token which is issued by the token endpoint of the Connector.
+	accessToken = "skypeapitest-access-token"
+	// The lifetime of the issued access tokens in seconds.
+	accessTokenExpiresIn = 3600
+	// The channel the key of the Signer of a Connector is endorsed for.
+	skypeChannelId = "skype"
+)
+
+// The RecordedActivity is a request which was sent to the conversation endpoints of a Connector.
+type RecordedActivity struct {
+	// The http method of the request.
+	Method string
+	// The path of the request.
+	Path string
+	// The value of the authorization header of the request.
+	Authorization string
+	// The id of the conversation which is addressed by the request.
+	ConversationId string
+	// The id of the activity which is addressed by the request. It is empty if the activity was sent to the
+	// end of the conversation.
+	ActivityId string
+	// The decoded activity. It is nil for requests without an activity like deletes.
+	Activity *skypeapi.Activity
+}
+
+// The Connector is an in-process fake of the Bot Connector service. It serves the OpenID metadata with the key
+// of its Signer, issues access tokens and records every activity which is sent to its conversation endpoints.
+type Connector struct {
+	// The server which serves the endpoints of the Connector.
+	Server *httptest.Server
+	// The Signer which issues the tokens of the inbound requests.
+	Signer *Signer
+	// The members which are returned by the member endpoints.
+	Members []skypeapi.ChannelAccount
+
+	mutex      sync.Mutex
+	activities []RecordedActivity
+	nextId     int
+}
+
+// Returns a new Connector which is started on a local port. It has to be closed with Close. The key of its Signer
+// is endorsed for the skype channel.
+func NewConnector() (*Connector, error) {
+	connector := &Connector{}
+	connector.Server = httptest.NewServer(http.HandlerFunc(connector.serveHTTP))
+	signer, err := NewSigner(connector.Server.URL)
+	if err != nil {
+		connector.Server.Close()
+		return nil, err
+	}
+	signer.Endorsements = []string{skypeChannelId}
+	connector.Signer = signer
+	return connector, nil
+}
+
+// Stops the server of the Connector.
+func (connector *Connector) Close() {
+	connector.Server.Close()
+}
+
+// Returns the url of the Connector which should be used as service url of the activities.
+func (connector *Connector) ServiceUrl() string {
+	return connector.Server.URL
+}
+
+// Returns a new Configuration which requests its tokens and keys from the Connector. The service url of the
+// Connector is trusted and failed requests are not retried.
+func (connector *Connector) Configuration() *skypeapi.Configuration {
+	configuration := skypeapi.NewConfiguration()
+	configuration.TokenUrl = connector.Server.URL + tokenPath
+	configuration.OpenIdMetadataUrl = connector.Server.URL + openIdMetadataPath
+	configuration.Issuer = connector.Signer.Issuer
+	configuration.HttpClient = connector.Server.Client()
+	configuration.EmulatorOpenIdMetadataUrl = connector.Server.URL + openIdMetadataPath
+	configuration.TrustedServiceUrls.Trust(connector.Server.URL)
+	return configuration
+}
+
+// Returns a copy of the activities which were sent to the Connector in the order they were received.
+func (connector *Connector) Activities() []RecordedActivity {
+	connector.mutex.Lock()
+	defer connector.mutex.Unlock()
+	return append([]RecordedActivity(nil), connector.activities...)
+}
+
+// Removes the recorded activities.
+func (connector *Connector) Reset() {
+	connector.mutex.Lock()
+	defer connector.mutex.Unlock()
+	connector.activities = nil
+}
+
+// Posts the activity to the handler like the channel would. The token is issued by the Signer of the Connector
+// for the microsoftAppId and the service url of the activity. If the activity has no service url the one of
+// the Connector is set.
+func (connector *Connector) PostActivity(handler http.Handler, microsoftAppId string, activity *skypeapi.Activity) (*httptest.ResponseRecorder, error) {
+	if activity.ServiceURL == "" {
+		activity.ServiceURL = connector.ServiceUrl()
+	}
+	token, err := connector.Signer.Token(microsoftAppId, activity.ServiceURL)
+	if err != nil {
+		return nil, err
+	}
+	return PostActivity(handler, token, activity)
+}
+
+// Posts the activity json encoded to the handler with the token as bearer authorization and returns the
+// recorded response. The handler is called synchronously.
+func PostActivity(handler http.Handler, token string, activity *skypeapi.Activity) (*httptest.ResponseRecorder, error) {
+	jsonEncoded, err := json.Marshal(activity)
+	if err != nil {
+		return nil, err
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(jsonEncoded))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	responseRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, req)
+	return responseRecorder, nil
+}
+
+func (connector *Connector) serveHTTP(responseWriter http.ResponseWriter, req *http.Request) {
+	switch {
+	case req.URL.Path == openIdMetadataPath:
+		writeJson(responseWriter, http.StatusOK, skypeapi.OpenIdDocument{
+			Issuer:                           connector.Signer.Issuer,
+			JwksURI:                          connector.Server.URL + signingKeysPath,
+			IDTokenSigningAlgValuesSupported: []string{signingAlgorithm},
+		})
+	case req.URL.Path == signingKeysPath:
+		writeJson(responseWriter, http.StatusOK, connector.Signer.SigningKeys())
+	case req.URL.Path == tokenPath && req.Method == http.MethodPost:
+		writeJson(responseWriter, http.StatusOK, skypeapi.TokenResponse{
+			TokenType:    "Bearer",
+			ExpiresIn:    accessTokenExpiresIn,
+			ExtExpiresIn: accessTokenExpiresIn,
+			AccessToken:  accessToken,
+		})
+	case req.URL.Path == conversationsPath && req.Method == http.MethodPost:
+		connector.createConversation(responseWriter, req)
+	case strings.HasPrefix(req.URL.Path, conversationsPath+"/"):
+		connector.serveConversation(responseWriter, req)
+	default:
+		http.NotFound(responseWriter, req)
+	}
+}
+
+func (connector *Connector) createConversation(responseWriter http.ResponseWriter, req *http.Request) {
+	var parameters skypeapi.ConversationParameters
+	if err := json.NewDecoder(req.Body).Decode(&parameters); err != nil {
+		writeError(responseWriter, http.StatusBadRequest, err.Error())
+		return
+	}
+	conversationId := connector.newId("conversation")
+	response := skypeapi.ConversationResourceResponse{ID: conversationId, ServiceURL: connector.ServiceUrl()}
+	if parameters.Activity != nil {
+		response.ActivityID = connector.record(req, conversationId, "", parameters.Activity)
+	}
+	writeJson(responseWriter, http.StatusCreated, response)
+}
+
+// Serves the endpoints below /v3/conversations/{conversationId}.
+func (connector *Connector) serveConversation(responseWriter http.ResponseWriter, req *http.Request) {
+	segments := strings.Split(strings.TrimPrefix(req.URL.Path, conversationsPath+"/"), "/")
+	conversationId := segments[0]
+	switch {
+	case len(segments) == 2 && segments[1] == "members" && req.Method == http.MethodGet:
+		writeJson(responseWriter, http.StatusOK, connector.members())
+	case len(segments) == 4 && segments[1] == "activities" && segments[3] == "members" && req.Method == http.MethodGet:
+		writeJson(responseWriter, http.StatusOK, connector.members())
+	case len(segments) == 2 && segments[1] == "attachments" && req.Method == http.MethodPost:
+		writeJson(responseWriter, http.StatusOK, skypeapi.ResourceResponse{ID: connector.newId("attachment")})
+	case len(segments) == 2 && segments[1] == "activities" && req.Method == http.MethodPost:
+		connector.receiveActivity(responseWriter, req, conversationId, "")
+	case len(segments) == 3 && segments[1] == "activities" && req.Method == http.MethodDelete:
+		connector.record(req, conversationId, segments[2], nil)
+		responseWriter.WriteHeader(http.StatusOK)
+	case len(segments) == 3 && segments[1] == "activities" &&
+		(req.Method == http.MethodPost || req.Method == http.MethodPut):
+		connector.receiveActivity(responseWriter, req, conversationId, segments[2])
+	default:
+		http.NotFound(responseWriter, req)
+	}
+}
+
+func (connector *Connector) receiveActivity(responseWriter http.ResponseWriter, req *http.Request, conversationId, activityId string) {
+	var activity skypeapi.Activity
+	if err := json.NewDecoder(req.Body).Decode(&activity); err != nil {
+		writeError(responseWriter, http.StatusBadRequest, err.Error())
+		return
+	}
+	id := connector.record(req, conversationId, activityId, &activity)
+	if req.Method == http.MethodPut {
+		id = activityId
+	}
+	writeJson(responseWriter, http.StatusOK, skypeapi.ResourceResponse{ID: id})
+}
+
+// Records the request and returns the id which is assigned to the activity.
+func (connector *Connector) record(req *http.Request, conversationId, activityId string, activity *skypeapi.Activity) string {
+	connector.mutex.Lock()
+	defer connector.mutex.Unlock()
+	connector.activities = append(connector.activities, RecordedActivity{
+		Method:         req.Method,
+		Path:           req.URL.Path,
+		Authorization:  req.Header.Get("Authorization"),
+		ConversationId: conversationId,
+		ActivityId:     activityId,
+		Activity:       activity,
+	})
+	connector.nextId++
+	return fmt.Sprintf("activity-%v", connector.nextId)
+}
+
+func (connector *Connector) newId(prefix string) string {
+	connector.mutex.Lock()
+	defer connector.mutex.Unlock()
+	connector.nextId++
+	return fmt.Sprintf("%v-%v", prefix, connector.nextId)
+}
+
+func (connector *Connector) members() []skypeapi.ChannelAccount {
+	connector.mutex.Lock()
+	defer connector.mutex.Unlock()
+	return append([]skypeapi.ChannelAccount{}, connector.Members...)
+}
+
+func writeJson(responseWriter http.ResponseWriter, statusCode int, value interface{}) {
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.WriteHeader(statusCode)
+	json.NewEncoder(responseWriter).Encode(value)
+}
+
+func writeError(responseWriter http.ResponseWriter, statusCode int, message string) {
+	writeJson(responseWriter, statusCode, skypeapi.ErrorResponse{
+		Error: skypeapi.ErrorDetail{Code: "BadArgument", Message: message},
+	})
+}
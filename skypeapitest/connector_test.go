@@ -0,0 +1,133 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapitest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/michivip/skypeapi"
+)
+
+const testAppId = "test-app-id"
+
+func newTestHandler(connector *Connector, handleFunction func(turn *skypeapi.TurnContext)) *skypeapi.EndpointHandler {
+	configuration := connector.Configuration()
+	accessTokenManager := skypeapi.NewAccessTokenManager(testAppId, "test-app-password")
+	accessTokenManager.Configuration = configuration
+	handler := skypeapi.NewTurnEndpointHandler(handleFunction, accessTokenManager, testAppId)
+	handler.Configuration = configuration
+	return handler
+}
+
+func newTestActivity() *skypeapi.Activity {
+	return &skypeapi.Activity{
+		Type:         skypeapi.MessageActivityType,
+		ID:           "activity-1",
+		ChannelID:    "skype",
+		From:         &skypeapi.ChannelAccount{ID: "29:user", Name: "User"},
+		Recipient:    &skypeapi.ChannelAccount{ID: "28:" + testAppId, Name: "Bot"},
+		Conversation: &skypeapi.ConversationAccount{ID: "29:user"},
+		Text:         "hello",
+	}
+}
+
+func TestConnectorRecordsReply(t *testing.T) {
+	connector, err := NewConnector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer connector.Close()
+	handler := newTestHandler(connector, func(turn *skypeapi.TurnContext) {
+		if _, err := turn.Reply("echo " + turn.Activity.Text); err != nil {
+			t.Errorf("the reply failed: %v", err)
+		}
+	})
+
+	responseRecorder, err := connector.PostActivity(handler, testAppId, newTestActivity())
+	if err != nil {
+		t.Fatal(err)
+	} else if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("got status code %v, expected %v", responseRecorder.Code, http.StatusOK)
+	}
+
+	activities := connector.Activities()
+	if len(activities) != 1 {
+		t.Fatalf("got %v recorded activities, expected 1", len(activities))
+	}
+	recorded := activities[0]
+	if recorded.Method != http.MethodPost || recorded.ConversationId != "29:user" || recorded.ActivityId != "activity-1" {
+		t.Errorf("the reply was sent to %v %v", recorded.Method, recorded.Path)
+	}
+	if recorded.Authorization != "Bearer "+accessToken {
+		t.Errorf("the reply was authorized with %q", recorded.Authorization)
+	}
+	if recorded.Activity == nil || recorded.Activity.Text != "echo hello" || recorded.Activity.ReplyToID != "activity-1" {
+		t.Errorf("got reply %+v", recorded.Activity)
+	} else if recorded.Activity.From == nil || recorded.Activity.From.ID != "28:"+testAppId {
+		t.Errorf("the reply was sent from %+v", recorded.Activity.From)
+	}
+}
+
+func TestConnectorRejectsServiceUrlMismatch(t *testing.T) {
+	connector, err := NewConnector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer connector.Close()
+	handled := false
+	handler := newTestHandler(connector, func(turn *skypeapi.TurnContext) {
+		handled = true
+	})
+	var authorizationError error
+	handler.AuthorizationFailedHandleFunction = func(req *http.Request, err error) {
+		authorizationError = err
+	}
+
+	// the token is issued for the service url of the connector but the activity names another one
+	token, err := connector.Signer.Token(testAppId, connector.ServiceUrl())
+	if err != nil {
+		t.Fatal(err)
+	}
+	activity := newTestActivity()
+	activity.ServiceURL = "https://attacker.example.com/"
+	responseRecorder, err := PostActivity(handler, token, activity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if responseRecorder.Code != http.StatusForbidden {
+		t.Errorf("got status code %v, expected %v", responseRecorder.Code, http.StatusForbidden)
+	}
+	if !errors.Is(authorizationError, skypeapi.ErrServiceUrlMismatch) {
+		t.Errorf("got error %v, expected %v", authorizationError, skypeapi.ErrServiceUrlMismatch)
+	}
+	if handled {
+		t.Error("the activity was handled although it was rejected")
+	}
+	if activities := connector.Activities(); len(activities) != 0 {
+		t.Errorf("got %v recorded activities, expected none", len(activities))
+	}
+}
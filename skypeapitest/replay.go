@@ -0,0 +1,130 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/michivip/skypeapi"
+)
+
+// The ReplayMismatchError describes an outbound activity of the bot which does not match the transcript.
+type ReplayMismatchError struct {
+	// The index of the inbound activity in the transcript whose outbound activities do not match.
+	Index int
+	// The outbound activities which were recorded in the transcript.
+	Expected []skypeapi.Activity
+	// The outbound activities which were sent by the bot.
+	Actual []skypeapi.Activity
+}
+
+func (replayMismatchError *ReplayMismatchError) Error() string {
+	return fmt.Sprintf("The bot replied to the activity %v with %v activities which do not match the %v recorded ones",
+		replayMismatchError.Index, len(replayMismatchError.Actual), len(replayMismatchError.Expected))
+}
+
+// Feeds the inbound activities of the transcript one after another into the handler with tokens of the Connector
+// and compares the activities the bot sends to the Connector with the outbound activities which follow in the
+// transcript. Activities whose From account has the bot role are outbound, every other activity is inbound. The
+// handler has to use the Configuration of the Connector and handle the activities synchronously. Returns a
+// ReplayMismatchError for the first inbound activity whose replies do not match.
+func (connector *Connector) Replay(handler http.Handler, microsoftAppId string, transcript []skypeapi.Activity) error {
+	for index := 0; index < len(transcript); index++ {
+		if isBotActivity(transcript[index]) {
+			continue
+		}
+		inbound := transcript[index]
+		inbound.ServiceURL = connector.ServiceUrl()
+		var expected []skypeapi.Activity
+		for next := index + 1; next < len(transcript) && isBotActivity(transcript[next]); next++ {
+			expected = append(expected, transcript[next])
+		}
+
+		connector.Reset()
+		if responseRecorder, err := connector.PostActivity(handler, microsoftAppId, &inbound); err != nil {
+			return err
+		} else if responseRecorder.Code != http.StatusOK {
+			return fmt.Errorf("The handler rejected the activity %v with the status code %v", index, responseRecorder.Code)
+		}
+		var actual []skypeapi.Activity
+		for _, recordedActivity := range connector.Activities() {
+			if recordedActivity.Activity != nil {
+				actual = append(actual, *recordedActivity.Activity)
+			}
+		}
+		if !activitiesMatch(expected, actual) {
+			return &ReplayMismatchError{Index: index, Expected: expected, Actual: actual}
+		}
+	}
+	return nil
+}
+
+func isBotActivity(activity skypeapi.Activity) bool {
+	return activity.From != nil && activity.From.Role == skypeapi.BotRole
+}
+
+// Compares the content of the activities. Ids, timestamps and addresses differ between a recording and a replay
+// so they are ignored.
+func activitiesMatch(expected, actual []skypeapi.Activity) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for index := range expected {
+		if !reflect.DeepEqual(activityContent(expected[index]), activityContent(actual[index])) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the json representation of the parts of the activity which are compared by a replay. The json
+// representation is used so values which were decoded from a transcript compare equal to the sent ones.
+func activityContent(activity skypeapi.Activity) interface{} {
+	jsonEncoded, _ := json.Marshal(map[string]interface{}{
+		"type":             activity.Type,
+		"action":           activity.Action,
+		"text":             activity.Text,
+		"textFormat":       activity.TextFormat,
+		"speak":            activity.Speak,
+		"inputHint":        activity.InputHint,
+		"summary":          activity.Summary,
+		"attachmentLayout": activity.AttachmentLayout,
+		"attachments":      activity.Attachments,
+		"suggestedActions": activity.SuggestedActions,
+		"entities":         activity.Entities,
+		"channelData":      activity.ChannelData,
+		"name":             activity.Name,
+		"value":            activity.Value,
+		"valueType":        activity.ValueType,
+		"importance":       activity.Importance,
+		"deliveryMode":     activity.DeliveryMode,
+		"semanticAction":   activity.SemanticAction,
+	})
+	var content interface{}
+	json.Unmarshal(jsonEncoded, &content)
+	return content
+}
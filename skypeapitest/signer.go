@@ -0,0 +1,140 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapitest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/michivip/skypeapi"
+)
+
+const (
+	// The algorithm the Signer signs its tokens with.
+	signingAlgorithm = "RS256"
+	// The lifetime of the tokens which are issued by the Signer.
+	tokenLifetime = time.Hour
+	// The size of the generated RSA keys in bits.
+	keySize = 2048
+)
+
+// The Signer holds a RSA key pair and issues tokens which are signed like the ones of the Bot Connector service.
+// Its SigningKey has to be provided to the EndpointHandler which should accept the tokens, for example via the
+// OpenID metadata of a Connector.
+type Signer struct {
+	// The id of the key which is set in the header of the issued tokens.
+	KeyId string
+	// The issuer which is set in the issued tokens.
+	Issuer string
+	// The channels the key is endorsed for. Activities of other channels are rejected by the EndpointHandler.
+	Endorsements []string
+	// The private key the tokens are signed with.
+	PrivateKey *rsa.PrivateKey
+}
+
+// Returns a new Signer with a generated RSA key pair which issues tokens with the given issuer.
+func NewSigner(issuer string) (*Signer, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, keySize)
+	if err != nil {
+		return nil, err
+	}
+	keyId := make([]byte, 8)
+	if _, err := rand.Read(keyId); err != nil {
+		return nil, err
+	}
+	return &Signer{
+		KeyId:      fmt.Sprintf("%x", keyId),
+		Issuer:     issuer,
+		PrivateKey: privateKey,
+	}, nil
+}
+
+// Returns the public key of the Signer as JWK with the modulus and exponent.
+func (signer *Signer) SigningKey() skypeapi.SigningKey {
+	return skypeapi.SigningKey{
+		Kty:          "RSA",
+		Use:          "sig",
+		KeyId:        signer.KeyId,
+		N:            base64.RawURLEncoding.EncodeToString(signer.PrivateKey.N.Bytes()),
+		E:            base64.RawURLEncoding.EncodeToString(big.NewInt(int64(signer.PrivateKey.E)).Bytes()),
+		Endorsements: signer.Endorsements,
+	}
+}
+
+// Returns the SigningKeys which contain only the key of the Signer.
+func (signer *Signer) SigningKeys() skypeapi.SigningKeys {
+	return skypeapi.SigningKeys{Keys: []skypeapi.SigningKey{signer.SigningKey()}}
+}
+
+// Returns a token for the microsoftAppId and the serviceUrl which is valid for one hour. The token could be used
+// as authorization header value prefixed with "Bearer ".
+func (signer *Signer) Token(microsoftAppId, serviceUrl string) (string, error) {
+	now := time.Now()
+	return signer.Sign(skypeapi.JwtPayload{
+		ServiceUrl:   serviceUrl,
+		Issuer:       signer.Issuer,
+		Audience:     microsoftAppId,
+		Expires:      int(now.Add(tokenLifetime).Unix()),
+		CreatedOnNbf: int(now.Unix()),
+	})
+}
+
+// Signs the payload with the key of the Signer. The payload is not modified so it could be used to issue expired
+// or otherwise invalid tokens.
+func (signer *Signer) Sign(payload skypeapi.JwtPayload) (string, error) {
+	header, err := encodeJsonPart(skypeapi.JwtHeader{
+		Type:         "JWT",
+		Algorithm:    signingAlgorithm,
+		SigningKeyId: signer.KeyId,
+	})
+	if err != nil {
+		return "", err
+	}
+	encodedPayload, err := encodeJsonPart(payload)
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + encodedPayload
+	hashed := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, signer.PrivateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func encodeJsonPart(part interface{}) (string, error) {
+	jsonEncoded, err := json.Marshal(part)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(jsonEncoded), nil
+}
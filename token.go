@@ -0,0 +1,238 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// The default duration before the expiry of an access token in which a new one is requested.
+	defaultTokenRefreshMargin = 5 * time.Minute
+	// The duration after which a failed refresh is retried.
+	tokenRefreshRetryInterval = 30 * time.Second
+	// The lifetime which is assumed for tokens whose response declares no positive lifetime.
+	minimumTokenLifetime = time.Minute
+)
+
+// A TokenSource supplies the bearer token which is attached to outgoing requests to the microsoft servers.
+type TokenSource interface {
+	// Returns a token which is valid at the time of the call.
+	Token() (string, error)
+}
+
+type staticTokenSource string
+
+// Returns a TokenSource which always returns the given token. The token is never refreshed so it should
+// only be used for short living applications or tests.
+func StaticTokenSource(token string) TokenSource {
+	return staticTokenSource(token)
+}
+
+func (token staticTokenSource) Token() (string, error) {
+	return string(token), nil
+}
+
+// A ContextTokenSource is a TokenSource which could abort waiting for a token if the ctx is done.
+type ContextTokenSource interface {
+	TokenSource
+	// Returns a token which is valid at the time of the call or the error of the ctx if it is done first.
+	TokenWithContext(ctx context.Context) (string, error)
+}
+
+// Returns a token of the tokenSource. The ctx is only respected if the tokenSource is a ContextTokenSource.
+func tokenFromSource(ctx context.Context, tokenSource TokenSource) (string, error) {
+	if contextTokenSource, ok := tokenSource.(ContextTokenSource); ok {
+		return contextTokenSource.TokenWithContext(ctx)
+	}
+	return tokenSource.Token()
+}
+
+// The AccessTokenManager is a TokenSource which requests its access tokens via Configuration.RequestAccessToken. The token is
+// cached and refreshed ahead of its expiry. It is safe for concurrent use and concurrent refreshes are
+// deduplicated so only one request at a time is sent to the microsoft servers.
+type AccessTokenManager struct {
+	// The MicrosoftAppId which is used to request the access token
+	MicrosoftAppId string
+	// The MicrosoftAppPassword which is used to request the access token
+	MicrosoftAppPassword string
+	// The duration before the expiry of the current token in which a new one is requested.
+	RefreshMargin time.Duration
+	// The Configuration which is used to request the access token. If it is nil the DefaultConfiguration is used.
+	Configuration *Configuration
+
+	mutex      sync.Mutex
+	token      string
+	refreshAt  time.Time
+	expiresAt  time.Time
+	refreshing *tokenRefresh
+
+	lifecycleMutex sync.Mutex
+	stop           context.CancelFunc
+	stopped        chan struct{}
+}
+
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// Returns a new AccessTokenManager with the default refresh margin of five minutes.
+func NewAccessTokenManager(microsoftAppId, microsoftAppPassword string) *AccessTokenManager {
+	return &AccessTokenManager{
+		MicrosoftAppId:       microsoftAppId,
+		MicrosoftAppPassword: microsoftAppPassword,
+		RefreshMargin:        defaultTokenRefreshMargin,
+	}
+}
+
+// Returns the cached access token or requests a new one if the cached token is about to expire. If the refresh
+// fails while the cached token is still valid the cached token is returned and the refresh is retried after
+// 30 seconds.
+func (accessTokenManager *AccessTokenManager) Token() (string, error) {
+	return accessTokenManager.TokenWithContext(context.Background())
+}
+
+// Returns the access token like Token. If the ctx is done before the token is refreshed its error is returned.
+// The refresh itself is not canceled because other callers could wait for it as well.
+func (accessTokenManager *AccessTokenManager) TokenWithContext(ctx context.Context) (string, error) {
+	accessTokenManager.mutex.Lock()
+	if accessTokenManager.token != "" && time.Now().Before(accessTokenManager.refreshAt) {
+		token := accessTokenManager.token
+		accessTokenManager.mutex.Unlock()
+		return token, nil
+	}
+	refresh := accessTokenManager.refreshing
+	if refresh == nil {
+		refresh = &tokenRefresh{done: make(chan struct{})}
+		accessTokenManager.refreshing = refresh
+		go accessTokenManager.refresh(refresh)
+	}
+	accessTokenManager.mutex.Unlock()
+	select {
+	case <-refresh.done:
+		return refresh.token, refresh.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Drops the cached access token so the next call of Token requests a new one. This could be used if the
+// microsoft servers rejected the current token.
+func (accessTokenManager *AccessTokenManager) Invalidate() {
+	accessTokenManager.mutex.Lock()
+	defer accessTokenManager.mutex.Unlock()
+	accessTokenManager.token = ""
+	accessTokenManager.refreshAt = time.Time{}
+	accessTokenManager.expiresAt = time.Time{}
+}
+
+// Starts a goroutine which refreshes the access token in the background before it is about to expire so callers
+// of Token do not have to wait for a refresh. Calling Start on a running manager has no effect.
+func (accessTokenManager *AccessTokenManager) Start() {
+	accessTokenManager.lifecycleMutex.Lock()
+	defer accessTokenManager.lifecycleMutex.Unlock()
+	if accessTokenManager.stop != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	accessTokenManager.stop = cancel
+	accessTokenManager.stopped = make(chan struct{})
+	go accessTokenManager.refreshLoop(ctx, accessTokenManager.stopped)
+}
+
+// Stops the background refresh which was started by Start and waits until the goroutine exited. The cached token
+// is still served and refreshed on demand afterwards.
+func (accessTokenManager *AccessTokenManager) Stop() {
+	accessTokenManager.lifecycleMutex.Lock()
+	defer accessTokenManager.lifecycleMutex.Unlock()
+	if accessTokenManager.stop == nil {
+		return
+	}
+	accessTokenManager.stop()
+	<-accessTokenManager.stopped
+	accessTokenManager.stop = nil
+	accessTokenManager.stopped = nil
+}
+
+func (accessTokenManager *AccessTokenManager) refreshLoop(ctx context.Context, stopped chan<- struct{}) {
+	defer close(stopped)
+	for {
+		wait := tokenRefreshRetryInterval
+		if _, err := accessTokenManager.TokenWithContext(ctx); err == nil {
+			accessTokenManager.mutex.Lock()
+			// a refresh time in the past must not make the loop spin
+			if untilRefresh := time.Until(accessTokenManager.refreshAt); untilRefresh > 0 {
+				wait = untilRefresh
+			}
+			accessTokenManager.mutex.Unlock()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (accessTokenManager *AccessTokenManager) refresh(refresh *tokenRefresh) {
+	requestedAt := time.Now()
+	tokenResponse, err := configurationOrDefault(accessTokenManager.Configuration).RequestAccessToken(
+		accessTokenManager.MicrosoftAppId, accessTokenManager.MicrosoftAppPassword)
+	accessTokenManager.mutex.Lock()
+	if err != nil {
+		if now := time.Now(); accessTokenManager.token != "" && now.Before(accessTokenManager.expiresAt) {
+			// the cached token is served until the retry so an outage does not cause a request on every call
+			accessTokenManager.refreshAt = now.Add(tokenRefreshRetryInterval)
+			if accessTokenManager.refreshAt.After(accessTokenManager.expiresAt) {
+				accessTokenManager.refreshAt = accessTokenManager.expiresAt
+			}
+			refresh.token = accessTokenManager.token
+		} else {
+			refresh.err = err
+		}
+	} else {
+		lifetime := time.Duration(tokenResponse.ExpiresIn) * time.Second
+		if lifetime <= 0 {
+			lifetime = minimumTokenLifetime
+		}
+		refreshMargin := accessTokenManager.RefreshMargin
+		// tokens with a short lifetime would otherwise be requested on every call
+		if refreshMargin > lifetime/2 {
+			refreshMargin = lifetime / 2
+		}
+		accessTokenManager.token = tokenResponse.AccessToken
+		accessTokenManager.refreshAt = requestedAt.Add(lifetime - refreshMargin)
+		accessTokenManager.expiresAt = requestedAt.Add(lifetime)
+		refresh.token = tokenResponse.AccessToken
+	}
+	accessTokenManager.refreshing = nil
+	accessTokenManager.mutex.Unlock()
+	close(refresh.done)
+}
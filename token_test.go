@@ -0,0 +1,92 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAccessTokenManagerBacksOffDuringOutage(t *testing.T) {
+	var requests, failing int32
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			responseWriter.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		responseWriter.Write([]byte(`{"token_type":"Bearer","expires_in":3600,"access_token":"cached"}`))
+	}))
+	defer server.Close()
+	configuration := NewConfiguration()
+	configuration.TokenUrl = server.URL
+	configuration.HttpClient = server.Client()
+	accessTokenManager := NewAccessTokenManager("app", "password")
+	accessTokenManager.Configuration = configuration
+
+	if _, err := accessTokenManager.Token(); err != nil {
+		t.Fatal(err)
+	}
+	// the token is about to expire and the token endpoint fails
+	accessTokenManager.mutex.Lock()
+	accessTokenManager.refreshAt = time.Now().Add(-time.Second)
+	accessTokenManager.mutex.Unlock()
+	atomic.StoreInt32(&failing, 1)
+
+	for i := 0; i < 100; i++ {
+		if token, err := accessTokenManager.Token(); err != nil || token != "cached" {
+			t.Fatalf("got token %q and error %v, expected the cached token", token, err)
+		}
+	}
+	if requests := atomic.LoadInt32(&requests); requests != 2 {
+		t.Errorf("got %v token requests, expected 2", requests)
+	}
+}
+
+func TestAccessTokenManagerWithoutLifetime(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		responseWriter.Write([]byte(`{"token_type":"Bearer","expires_in":0,"access_token":"token"}`))
+	}))
+	defer server.Close()
+	configuration := NewConfiguration()
+	configuration.TokenUrl = server.URL
+	configuration.HttpClient = server.Client()
+	accessTokenManager := NewAccessTokenManager("app", "password")
+	accessTokenManager.Configuration = configuration
+
+	accessTokenManager.Start()
+	time.Sleep(100 * time.Millisecond)
+	accessTokenManager.Stop()
+	for i := 0; i < 10; i++ {
+		accessTokenManager.Token()
+	}
+	if requests := atomic.LoadInt32(&requests); requests != 1 {
+		t.Errorf("got %v token requests, expected 1", requests)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// The TranscriptRecorder writes the inbound and outbound activities of a bot as JSON lines to a writer. The From
+// account of every recorded activity carries the role of the sender so a transcript could be replayed later on.
+// Files in the .transcript format of the Bot Framework could be read with ReadTranscript as well.
+type TranscriptRecorder struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+// Returns a new TranscriptRecorder which writes the activities to the writer.
+func NewTranscriptRecorder(writer io.Writer) *TranscriptRecorder {
+	return &TranscriptRecorder{encoder: json.NewEncoder(writer)}
+}
+
+// Writes the activity as a single line. If the From account has no role the given role is set on the written copy.
+func (transcriptRecorder *TranscriptRecorder) Record(activity *Activity, role RoleType) error {
+	recorded := *activity
+	var from ChannelAccount
+	if recorded.From != nil {
+		from = *recorded.From
+	}
+	if from.Role == "" {
+		from.Role = role
+	}
+	recorded.From = &from
+	transcriptRecorder.mutex.Lock()
+	defer transcriptRecorder.mutex.Unlock()
+	return transcriptRecorder.encoder.Encode(&recorded)
+}
+
+// Returns a Middleware which records every inbound activity with the user role before it is passed on.
+func (transcriptRecorder *TranscriptRecorder) Middleware() Middleware {
+	return func(activity *Activity, next func(activity *Activity)) {
+		transcriptRecorder.Record(activity, UserRole)
+		next(activity)
+	}
+}
+
+// Returns a http.RoundTripper which records every activity the bot sends to a conversation with the bot role
+// once the base returned a successful response. Failed attempts are not recorded so a retried send is only
+// recorded once. If the base is nil the http.DefaultTransport is used. It could be set
+// as Transport of the http.Client of the Configuration.
+func (transcriptRecorder *TranscriptRecorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transcriptTransport{transcriptRecorder: transcriptRecorder, base: base}
+}
+
+type transcriptTransport struct {
+	transcriptRecorder *TranscriptRecorder
+	base               http.RoundTripper
+}
+
+func (transport *transcriptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || !isActivityRequest(req) {
+		return transport.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// the request must not be modified by a http.RoundTripper so the body is replaced on a copy
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := transport.base.RoundTrip(req)
+	if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		var activity Activity
+		if json.Unmarshal(body, &activity) == nil {
+			transport.transcriptRecorder.Record(&activity, BotRole)
+		}
+	}
+	return resp, err
+}
+
+// Returns true if the request sends an activity to the conversations api.
+func isActivityRequest(req *http.Request) bool {
+	if req.Method != http.MethodPost && req.Method != http.MethodPut {
+		return false
+	}
+	index := strings.Index(req.URL.Path, "/v3/conversations/")
+	return index >= 0 && strings.Contains(req.URL.Path[index:], "/activities")
+}
+
+// Reads the activities of a transcript. The transcript could either consist of JSON lines as written by the
+// TranscriptRecorder or of a single JSON array as in the .transcript files of the Bot Framework.
+func ReadTranscript(reader io.Reader) ([]Activity, error) {
+	bufferedReader := bufio.NewReader(reader)
+	if isJsonArray, err := startsWithJsonArray(bufferedReader); err != nil {
+		return nil, err
+	} else if isJsonArray {
+		var activities []Activity
+		err := json.NewDecoder(bufferedReader).Decode(&activities)
+		return activities, err
+	}
+	var activities []Activity
+	decoder := json.NewDecoder(bufferedReader)
+	for {
+		var activity Activity
+		if err := decoder.Decode(&activity); err == io.EOF {
+			return activities, nil
+		} else if err != nil {
+			return activities, err
+		}
+		activities = append(activities, activity)
+	}
+}
+
+func startsWithJsonArray(bufferedReader *bufio.Reader) (bool, error) {
+	for {
+		character, err := bufferedReader.ReadByte()
+		if err == io.EOF {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		switch character {
+		case ' ', '\t', '\r', '\n':
+			continue
+		default:
+			return character == '[', bufferedReader.UnreadByte()
+		}
+	}
+}
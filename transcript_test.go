@@ -0,0 +1,73 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTranscriptTransportRecordsDeliveredActivitiesOnce(t *testing.T) {
+	tests := []struct {
+		statusCodes     []int
+		expectedRecords int
+	}{
+		{[]int{http.StatusOK}, 1},
+		{[]int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusCreated}, 1},
+		{[]int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable}, 0},
+		{[]int{http.StatusBadRequest}, 0},
+	}
+	for _, test := range tests {
+		var attempts int32
+		statusCodes := test.statusCodes
+		server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, req *http.Request) {
+			attempt := atomic.AddInt32(&attempts, 1)
+			responseWriter.WriteHeader(statusCodes[int(attempt)-1])
+			responseWriter.Write([]byte(`{"id":"sent"}`))
+		}))
+		var transcript bytes.Buffer
+		transcriptRecorder := NewTranscriptRecorder(&transcript)
+		configuration := &Configuration{
+			HttpClient:  &http.Client{Transport: transcriptRecorder.Transport(server.Client().Transport)},
+			RetryPolicy: newTestRetryPolicy(),
+		}
+
+		sendJsonRequest(context.Background(), configuration, StaticTokenSource("token"), http.MethodPost,
+			server.URL+"/v3/conversations/a/activities", &Activity{Type: MessageActivityType, Text: "hello"}, nil)
+		server.Close()
+
+		activities, err := ReadTranscript(&transcript)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(activities) != test.expectedRecords {
+			t.Errorf("%v: got %v recorded activities, expected %v", test.statusCodes, len(activities), test.expectedRecords)
+		} else if len(activities) == 1 && (activities[0].Text != "hello" || activities[0].From.Role != BotRole) {
+			t.Errorf("%v: got recorded activity %+v", test.statusCodes, activities[0])
+		}
+	}
+}
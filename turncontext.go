@@ -0,0 +1,106 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import "context"
+
+// The TurnContext bundles an incoming Activity with everything which is needed to respond to it. The Client is
+// bound to the service url of the activity so handle functions do not need to deal with tokens or urls.
+type TurnContext struct {
+	// The incoming activity.
+	Activity *Activity
+	// The context of the turn. It is done if the incoming request was aborted or the handler shuts down.
+	Context context.Context
+	// The ConnectorClient which sends its requests to the service url of the activity.
+	Client *ConnectorClient
+}
+
+// Returns a new TurnContext for the activity. The ConnectorClient is authorized by the tokenSource and sends its
+// requests with the configuration which could be nil to use the DefaultConfiguration.
+func NewTurnContext(ctx context.Context, activity *Activity, tokenSource TokenSource, configuration *Configuration) *TurnContext {
+	client := NewConnectorClient(activity.ServiceURL, tokenSource)
+	client.Configuration = configuration
+	return &TurnContext{
+		Activity: activity,
+		Context:  ctx,
+		Client:   client,
+	}
+}
+
+// Sends the text as a reply message to the activity of the turn.
+func (turnContext *TurnContext) Reply(text string) (ResourceResponse, error) {
+	return turnContext.ReplyActivity(&Activity{
+		Type: MessageActivityType,
+		Text: text,
+	})
+}
+
+// Sends the activity as a reply to the activity of the turn. The addressing fields which are not set yet are
+// filled from the incoming activity.
+func (turnContext *TurnContext) ReplyActivity(activity *Activity) (ResourceResponse, error) {
+	turnContext.address(activity)
+	if activity.ReplyToID == "" {
+		activity.ReplyToID = turnContext.Activity.ID
+	}
+	return turnContext.Client.ReplyToActivityWithContext(turnContext.Context, turnContext.Activity.conversationId(),
+		turnContext.Activity.ID, activity)
+}
+
+// Sends an activity to the conversation of the turn without replying to a specific activity.
+func (turnContext *TurnContext) Send(activity *Activity) (ResourceResponse, error) {
+	turnContext.address(activity)
+	return turnContext.Client.SendToConversationWithContext(turnContext.Context, turnContext.Activity.conversationId(), activity)
+}
+
+// Sends a typing indicator to the conversation of the turn.
+func (turnContext *TurnContext) SendTyping() error {
+	_, err := turnContext.Send(&Activity{Type: TypingActivityType})
+	return err
+}
+
+// Replaces the previously sent activity with the given activityId in the conversation of the turn.
+func (turnContext *TurnContext) Update(activityId string, activity *Activity) (ResourceResponse, error) {
+	turnContext.address(activity)
+	activity.ID = activityId
+	return turnContext.Client.UpdateActivityWithContext(turnContext.Context, turnContext.Activity.conversationId(),
+		activityId, activity)
+}
+
+// Deletes the previously sent activity with the given activityId from the conversation of the turn.
+func (turnContext *TurnContext) Delete(activityId string) error {
+	return turnContext.Client.DeleteActivityWithContext(turnContext.Context, turnContext.Activity.conversationId(), activityId)
+}
+
+// Fills the sender, recipient and conversation of the outgoing activity from the incoming one if they are not set.
+func (turnContext *TurnContext) address(activity *Activity) {
+	if activity.fromId() == "" {
+		activity.From = turnContext.Activity.Recipient
+	}
+	if activity.recipientId() == "" {
+		activity.Recipient = turnContext.Activity.From
+	}
+	if activity.conversationId() == "" {
+		activity.Conversation = turnContext.Activity.Conversation
+	}
+}
@@ -0,0 +1,126 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"time"
+)
+
+const (
+	// The algorithm which is used by the Bot Connector service to sign its tokens.
+	rs256Algorithm = "RS256"
+	// The default tolerance for differences between the clocks of the microsoft servers and the own server.
+	defaultClockSkew = 5 * time.Minute
+
+	unsupportedAlgorithmError = "The token is signed with an unsupported algorithm: %v"
+	wrongIssuerError          = "The token was issued by an unexpected issuer: %v"
+	wrongAudienceError        = "The token was issued for an unexpected audience: %v"
+	tokenExpiredError         = "The token expired at %v"
+	tokenNotYetValidError     = "The token is not valid before %v"
+	unknownKeyIdError         = "The token is signed with an unknown key: %v"
+	badSignatureError         = "The signature of the token is not valid"
+	missingEndorsementError   = "The signing key of the token is not endorsed for the channel: %v"
+	missingChannelIdError     = "The activity does not declare a channel"
+	serviceUrlMismatchError   = "The service url of the token %v does not match the service url of the activity %v"
+)
+
+// The TokenValidator validates the tokens of incoming requests as described in:
+// https://docs.microsoft.com/en-us/bot-framework/rest-api/bot-framework-rest-connector-authentication
+type TokenValidator struct {
+	// The issuers which are accepted.
+	Issuers []string
+	// The signing algorithms which are accepted.
+	Algorithms []string
+	// The tolerance for differences between the clocks of the issuer and the own server which is applied to
+	// the expiry and the not before time of the token.
+	ClockSkew time.Duration
+}
+
+// Returns a new TokenValidator which accepts RS256 signed tokens of the issuer of the configuration with a clock
+// skew of five minutes. If the configuration is nil the DefaultConfiguration is used. Validators of other issuers
+// like local test servers could accept RS384 and RS512 as well by adding them to the Algorithms.
+func NewTokenValidator(configuration *Configuration) *TokenValidator {
+	return &TokenValidator{
+		Issuers:    []string{configurationOrDefault(configuration).Issuer},
+		Algorithms: []string{rs256Algorithm},
+		ClockSkew:  defaultClockSkew,
+	}
+}
+
+// Validates the algorithm, issuer, audience, expiry, not before time and signature of the token. If the channelId
+// is not empty the signing key has to be endorsed for the channel as well. The returned error is an
+// AuthorizationError.
+func (tokenValidator *TokenValidator) Validate(microsoftJsonWebToken MicrosoftJsonWebToken, microsoftAppId string, signingKeys SigningKeys, channelId string) error {
+	now := time.Now()
+	header, payload := microsoftJsonWebToken.Header, microsoftJsonWebToken.Payload
+	if !containsString(tokenValidator.Algorithms, header.Algorithm) {
+		return newAuthorizationError(ErrUnsupportedAlgorithm, unsupportedAlgorithmError, header.Algorithm)
+	} else if !containsString(tokenValidator.Issuers, payload.Issuer) {
+		return newAuthorizationError(ErrWrongIssuer, wrongIssuerError, payload.Issuer)
+	} else if payload.Audience != microsoftAppId {
+		return newAuthorizationError(ErrWrongAudience, wrongAudienceError, payload.Audience)
+	} else if expires := time.Unix(int64(payload.Expires), 0); !now.Before(expires.Add(tokenValidator.ClockSkew)) {
+		return newAuthorizationError(ErrTokenExpired, tokenExpiredError, expires)
+	} else if notBefore := time.Unix(int64(payload.CreatedOnNbf), 0); payload.CreatedOnNbf != 0 &&
+		now.Add(tokenValidator.ClockSkew).Before(notBefore) {
+		return newAuthorizationError(ErrTokenNotYetValid, tokenNotYetValidError, notBefore)
+	}
+	signingKey, ok := signingKeys.key(header.SigningKeyId)
+	if !ok {
+		return newAuthorizationError(ErrUnknownKeyId, unknownKeyIdError, header.SigningKeyId)
+	} else if err := microsoftJsonWebToken.verifySignature(signingKey); err != nil {
+		return err
+	}
+	return ValidateEndorsement(signingKey, channelId)
+}
+
+// Validates that the signing key is endorsed for the channel. Keys without endorsements are not endorsed for any
+// channel. If the channelId is empty the endorsements are not checked so the channel of the activity has to be
+// validated before.
+func ValidateEndorsement(signingKey SigningKey, channelId string) error {
+	if channelId == "" || containsString(signingKey.Endorsements, channelId) {
+		return nil
+	}
+	return newAuthorizationError(ErrMissingEndorsement, missingEndorsementError, channelId)
+}
+
+// Validates that the token was issued for the service url of the activity. Otherwise a token of one channel could
+// be used to make the bot send its credentials to an arbitrary url.
+func ValidateServiceUrl(microsoftJsonWebToken MicrosoftJsonWebToken, serviceUrl string) error {
+	tokenServiceUrl, _ := normalizeServiceUrl(microsoftJsonWebToken.Payload.ServiceUrl)
+	activityServiceUrl, ok := normalizeServiceUrl(serviceUrl)
+	if !ok || tokenServiceUrl != activityServiceUrl {
+		return newAuthorizationError(ErrServiceUrlMismatch, serviceUrlMismatchError, microsoftJsonWebToken.Payload.ServiceUrl, serviceUrl)
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+/*
+MIT License
+
+Copyright (c) 2017 MichiVIP
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+package skypeapi
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+const (
+	testAppId      = "test-app-id"
+	testKeyId      = "test-key-id"
+	testIssuer     = "https://api.botframework.com"
+	testServiceUrl = "https://smba.trafficmanager.net/apis/"
+)
+
+func newTestPrivateKey(t *testing.T) *rsa.PrivateKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privateKey
+}
+
+// Returns the public key of the privateKey as JWK with the modulus and exponent.
+func newTestSigningKey(privateKey *rsa.PrivateKey, endorsements ...string) SigningKey {
+	return SigningKey{
+		Kty:          "RSA",
+		Use:          "sig",
+		KeyId:        testKeyId,
+		N:            base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+		E:            base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+		Endorsements: endorsements,
+	}
+}
+
+// Returns a payload which is accepted by the validator of testIssuer for testAppId.
+func newTestPayload() JwtPayload {
+	now := time.Now()
+	return JwtPayload{
+		ServiceUrl:   testServiceUrl,
+		Issuer:       testIssuer,
+		Audience:     testAppId,
+		Expires:      int(now.Add(time.Hour).Unix()),
+		CreatedOnNbf: int(now.Unix()),
+	}
+}
+
+// Signs the header and payload with the privateKey and parses the result like an incoming authorization header.
+// The signature is left empty if the algorithm is not a supported RSA algorithm.
+func signTestToken(t *testing.T, privateKey *rsa.PrivateKey, header JwtHeader, payload JwtPayload) MicrosoftJsonWebToken {
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedPayload, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(encodedHeader) + splitCharacter +
+		base64.RawURLEncoding.EncodeToString(encodedPayload)
+	var signature []byte
+	if hash, ok := signatureHashes[header.Algorithm]; ok {
+		hasher := hash.New()
+		hasher.Write([]byte(signingInput))
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, privateKey, hash, hasher.Sum(nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	microsoftJsonWebToken, err := ParseMicrosoftJsonWebToken(authorizationHeaderValuePrefix + signingInput +
+		splitCharacter + base64.RawURLEncoding.EncodeToString(signature))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return microsoftJsonWebToken
+}
+
+func TestTokenValidatorValidate(t *testing.T) {
+	privateKey := newTestPrivateKey(t)
+	otherPrivateKey := newTestPrivateKey(t)
+	now := time.Now()
+	tests := []struct {
+		name         string
+		header       func(header *JwtHeader)
+		payload      func(payload *JwtPayload)
+		validator    func(tokenValidator *TokenValidator)
+		signingKey   *rsa.PrivateKey
+		endorsements []string
+		channelId    string
+		expected     error
+	}{
+		{name: "valid"},
+		{name: "without not before", payload: func(payload *JwtPayload) { payload.CreatedOnNbf = 0 }},
+		{name: "expired within clock skew", payload: func(payload *JwtPayload) {
+			payload.Expires = int(now.Add(-defaultClockSkew + time.Minute).Unix())
+		}},
+		{name: "expired beyond clock skew", payload: func(payload *JwtPayload) {
+			payload.Expires = int(now.Add(-defaultClockSkew - time.Minute).Unix())
+		}, expected: ErrTokenExpired},
+		{name: "expired without clock skew", payload: func(payload *JwtPayload) {
+			payload.Expires = int(now.Add(-time.Minute).Unix())
+		}, validator: func(tokenValidator *TokenValidator) { tokenValidator.ClockSkew = 0 }, expected: ErrTokenExpired},
+		{name: "not before within clock skew", payload: func(payload *JwtPayload) {
+			payload.CreatedOnNbf = int(now.Add(defaultClockSkew - time.Minute).Unix())
+		}},
+		{name: "not before beyond clock skew", payload: func(payload *JwtPayload) {
+			payload.CreatedOnNbf = int(now.Add(defaultClockSkew + time.Minute).Unix())
+		}, expected: ErrTokenNotYetValid},
+		{name: "not before without clock skew", payload: func(payload *JwtPayload) {
+			payload.CreatedOnNbf = int(now.Add(time.Minute).Unix())
+		}, validator: func(tokenValidator *TokenValidator) { tokenValidator.ClockSkew = 0 }, expected: ErrTokenNotYetValid},
+		{name: "RS384 by default", header: func(header *JwtHeader) { header.Algorithm = rs384Algorithm },
+			expected: ErrUnsupportedAlgorithm},
+		{name: "RS384 if accepted", header: func(header *JwtHeader) { header.Algorithm = rs384Algorithm },
+			validator: func(tokenValidator *TokenValidator) {
+				tokenValidator.Algorithms = append(tokenValidator.Algorithms, rs384Algorithm)
+			}},
+		{name: "HS256", header: func(header *JwtHeader) { header.Algorithm = "HS256" }, expected: ErrUnsupportedAlgorithm},
+		{name: "none", header: func(header *JwtHeader) { header.Algorithm = "none" }, expected: ErrUnsupportedAlgorithm},
+		{name: "none if accepted", header: func(header *JwtHeader) { header.Algorithm = "none" },
+			validator: func(tokenValidator *TokenValidator) {
+				tokenValidator.Algorithms = append(tokenValidator.Algorithms, "none")
+			}, expected: ErrUnsupportedAlgorithm},
+		{name: "wrong issuer", payload: func(payload *JwtPayload) { payload.Issuer = "https://evil.example.com" },
+			expected: ErrWrongIssuer},
+		{name: "wrong audience", payload: func(payload *JwtPayload) { payload.Audience = "other-app-id" },
+			expected: ErrWrongAudience},
+		{name: "empty audience", payload: func(payload *JwtPayload) { payload.Audience = "" },
+			expected: ErrWrongAudience},
+		{name: "unknown key id", header: func(header *JwtHeader) { header.SigningKeyId = "other-key-id" },
+			expected: ErrUnknownKeyId},
+		{name: "signed by another key", signingKey: otherPrivateKey, expected: ErrBadSignature},
+		{name: "endorsed channel", endorsements: []string{"msteams", "skype"}, channelId: "skype"},
+		{name: "not endorsed channel", endorsements: []string{"msteams"}, channelId: "skype",
+			expected: ErrMissingEndorsement},
+		{name: "key without endorsements", channelId: "skype", expected: ErrMissingEndorsement},
+		{name: "without channel", endorsements: []string{"msteams"}},
+	}
+	for _, test := range tests {
+		header := JwtHeader{Type: "JWT", Algorithm: rs256Algorithm, SigningKeyId: testKeyId}
+		if test.header != nil {
+			test.header(&header)
+		}
+		payload := newTestPayload()
+		if test.payload != nil {
+			test.payload(&payload)
+		}
+		signingKey := privateKey
+		if test.signingKey != nil {
+			signingKey = test.signingKey
+		}
+		tokenValidator := NewTokenValidator(&Configuration{Issuer: testIssuer})
+		if test.validator != nil {
+			test.validator(tokenValidator)
+		}
+		signingKeys := SigningKeys{Keys: []SigningKey{newTestSigningKey(privateKey, test.endorsements...)}}
+
+		err := tokenValidator.Validate(signTestToken(t, signingKey, header, payload), testAppId, signingKeys, test.channelId)
+		if test.expected == nil && err != nil {
+			t.Errorf("%v: got error %v, expected none", test.name, err)
+		} else if test.expected != nil && !errors.Is(err, test.expected) {
+			t.Errorf("%v: got error %v, expected %v", test.name, err, test.expected)
+		} else if _, ok := err.(*AuthorizationError); err != nil && !ok {
+			t.Errorf("%v: got error %T, expected an AuthorizationError", test.name, err)
+		}
+	}
+}
+
+func TestValidateEndorsement(t *testing.T) {
+	tests := []struct {
+		endorsements []string
+		channelId    string
+		expected     error
+	}{
+		{[]string{"skype"}, "skype", nil},
+		{[]string{"msteams", "skype"}, "skype", nil},
+		{[]string{"msteams"}, "skype", ErrMissingEndorsement},
+		{[]string{"Skype"}, "skype", ErrMissingEndorsement},
+		{nil, "skype", ErrMissingEndorsement},
+		{nil, "", nil},
+	}
+	for _, test := range tests {
+		err := ValidateEndorsement(SigningKey{KeyId: testKeyId, Endorsements: test.endorsements}, test.channelId)
+		if test.expected == nil && err != nil {
+			t.Errorf("%v %q: got error %v, expected none", test.endorsements, test.channelId, err)
+		} else if test.expected != nil && !errors.Is(err, test.expected) {
+			t.Errorf("%v %q: got error %v, expected %v", test.endorsements, test.channelId, err, test.expected)
+		}
+	}
+}